@@ -0,0 +1,165 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/jwt"
+	"github.com/spf13/cobra"
+)
+
+func createAccountAcceptCmd() *cobra.Command {
+	var params AccountAcceptParams
+	cmd := &cobra.Command{
+		Use:   "accept <signed jwt>",
+		Short: "Accept an account JWT signed by the operator",
+		Long: `Accept an account JWT signed by the operator
+
+The JWT replaces the account with the same public key, when it is signed by
+the operator of the managed store and has the same content as the account.
+The operator public key is set with 'nsc init --create-operator=false
+--operator-key', or on the first accept with --operator-key.`,
+		Example: `nsc account accept A.jwt
+nsc account accept --operator-key ODWZ... A.jwt`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			cmd.Printf("Success! - accepted account %q signed by %s\n", params.name, params.claim.Issuer)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.operatorKey, "operator-key", "", "", "public key of the operator, if the store doesn't have one")
+
+	return cmd
+}
+
+func init() {
+	accountCmd.AddCommand(createAccountAcceptCmd())
+}
+
+type AccountAcceptParams struct {
+	operatorKey string
+	name        string
+	token       string
+	claim       *jwt.AccountClaims
+	local       *jwt.AccountClaims
+}
+
+func (p *AccountAcceptParams) SetDefaults(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+	if !s.IsManaged() {
+		return errors.New("only accounts of managed operators are accepted - the operator of the store signs its accounts")
+	}
+	if s.Info.OperatorKey != "" && p.operatorKey != "" && s.Info.OperatorKey != p.operatorKey {
+		return fmt.Errorf("the store already has the operator key %s", s.Info.OperatorKey)
+	}
+	return nil
+}
+
+func (p *AccountAcceptParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *AccountAcceptParams) Load(ctx ActionCtx) error {
+	fp := ctx.Args()[0]
+	d, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", fp, err)
+	}
+	p.token = ExtractToken(string(d))
+	if p.token == "" {
+		p.token = strings.TrimSpace(string(d))
+	}
+	p.claim, err = jwt.DecodeAccountClaims(p.token)
+	if err != nil {
+		return fmt.Errorf("%q is not an account JWT: %v", fp, err)
+	}
+
+	s := ctx.StoreCtx().Store
+	if p.name, err = s.FindAccount(p.claim.Subject); err != nil {
+		return err
+	}
+	if p.name == "" {
+		return fmt.Errorf("account %s is not in the store", p.claim.Subject)
+	}
+	p.local, err = s.ReadAccountClaim(p.name)
+	return err
+}
+
+func (p *AccountAcceptParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *AccountAcceptParams) Validate(ctx ActionCtx) error {
+	operator := ctx.StoreCtx().Store.Info.OperatorKey
+	if operator == "" {
+		operator = p.operatorKey
+	}
+	if operator == "" {
+		ctx.CurrentCmd().SilenceUsage = false
+		return errors.New("the store doesn't have an operator key - specify --operator-key")
+	}
+	if p.claim.Issuer != operator {
+		return fmt.Errorf("the JWT is signed by %s and not by the operator %s", p.claim.Issuer, operator)
+	}
+
+	a, err := accountContent(p.local)
+	if err != nil {
+		return err
+	}
+	b, err := accountContent(p.claim)
+	if err != nil {
+		return err
+	}
+	if a != b {
+		return fmt.Errorf("the JWT changes the content of account %q", p.name)
+	}
+	return nil
+}
+
+// accountContent serializes the parts of the account the operator can't change
+func accountContent(ac *jwt.AccountClaims) (string, error) {
+	tags := append([]string(nil), ac.Tags...)
+	sort.Strings(tags)
+	d, err := json.Marshal(struct {
+		Name    string
+		Tags    []string
+		Account jwt.Account
+	}{ac.Name, tags, ac.Account})
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+func (p *AccountAcceptParams) Run(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+	if s.Info.OperatorKey == "" {
+		if err := s.SetOperatorKey(p.operatorKey); err != nil {
+			return err
+		}
+	}
+	return s.StoreClaim([]byte(p.token))
+}
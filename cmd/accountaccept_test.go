@@ -0,0 +1,101 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/stretchr/testify/require"
+)
+
+// signRequest signs the account of the request as the external operator
+func signRequest(t *testing.T, ts *TestStore, operator nkeys.KeyPair, account string, name string, edit func(ac *jwt.AccountClaims)) string {
+	req := filepath.Join(ts.Dir, name+".request.jwt")
+	_, _, err := ExecuteCmd(createAccountRequestCmd(), "--account", account, "-o", req)
+	require.NoError(t, err)
+	d, err := ioutil.ReadFile(req)
+	require.NoError(t, err)
+	ac, err := jwt.DecodeAccountClaims(ExtractToken(string(d)))
+	require.NoError(t, err)
+	if edit != nil {
+		edit(ac)
+	}
+	token, err := ac.Encode(operator)
+	require.NoError(t, err)
+
+	fp := filepath.Join(ts.Dir, name+".jwt")
+	require.NoError(t, ioutil.WriteFile(fp, FormatJwt("Account", token), 0600))
+	return fp
+}
+
+func Test_AccountAccept(t *testing.T) {
+	ts := NewTestStoreWithOperator(t, "test", nil)
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	_, opk, okp := CreateOperatorKey(t)
+	fp := signRequest(t, ts, okp, "A", "A", nil)
+
+	_, _, err := ExecuteCmd(createAccountAcceptCmd(), fp)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "specify --operator-key")
+
+	_, stderr, err := ExecuteCmd(createAccountAcceptCmd(), "--operator-key", string(opk), fp)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "accepted account \"A\"")
+
+	ac, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Equal(t, string(opk), ac.Issuer)
+
+	// the operator key is recorded by the first accept
+	s, err := GetStore()
+	require.NoError(t, err)
+	require.Equal(t, string(opk), s.Info.OperatorKey)
+	_, _, err = ExecuteCmd(createAccountAcceptCmd(), fp)
+	require.NoError(t, err)
+}
+
+func Test_AccountAcceptRejects(t *testing.T) {
+	ts := NewTestStoreWithOperator(t, "test", nil)
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	_, opk, okp := CreateOperatorKey(t)
+	_, _, other := CreateOperatorKey(t)
+	require.NoError(t, ts.Store.SetOperatorKey(string(opk)))
+
+	_, otherPub, _ := CreateOperatorKey(t)
+	wrongSigner := signRequest(t, ts, other, "A", "wrong", nil)
+	changed := signRequest(t, ts, okp, "A", "changed", func(ac *jwt.AccountClaims) {
+		ac.Limits.Conn = 10
+	})
+
+	tests := CmdTests{
+		{createAccountAcceptCmd(), []string{"account", "accept", wrongSigner}, nil, []string{"and not by the operator"}, true},
+		{createAccountAcceptCmd(), []string{"account", "accept", changed}, nil, []string{"changes the content of account \"A\""}, true},
+		{createAccountAcceptCmd(), []string{"account", "accept", "--operator-key", string(otherPub), changed}, nil, []string{"the store already has the operator key"}, true},
+	}
+	tests.Run(t, "root", "account")
+
+	ac, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Equal(t, ac.Subject, ac.Issuer)
+}
@@ -23,10 +23,11 @@ import (
 
 type AccountContextParams struct {
 	Name string
+	err  error
 }
 
 func (p *AccountContextParams) BindFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(&p.Name, "account", "a", "", "account name")
+	cmd.Flags().StringVarP(&p.Name, "account", "a", "", "account name or public key prefix")
 }
 
 func (p *AccountContextParams) SetDefaults(ctx ActionCtx) {
@@ -40,6 +41,23 @@ func (p *AccountContextParams) SetDefaults(ctx ActionCtx) {
 	if ctx.StoreCtx().Account.Name != "" && p.Name == "" {
 		p.Name = ctx.StoreCtx().Account.Name
 	}
+	p.resolve(ctx)
+}
+
+// resolve replaces a public key prefix with the name of the account
+func (p *AccountContextParams) resolve(ctx ActionCtx) {
+	if p.Name == "" {
+		return
+	}
+	n, err := ctx.StoreCtx().Store.ResolveAccount(p.Name)
+	if err != nil {
+		p.err = err
+		return
+	}
+	if ctx.StoreCtx().Account.Name == p.Name {
+		ctx.StoreCtx().Account.Name = n
+	}
+	p.Name = n
 }
 
 func (p *AccountContextParams) Edit(ctx ActionCtx) error {
@@ -48,6 +66,7 @@ func (p *AccountContextParams) Edit(ctx ActionCtx) error {
 	if err != nil {
 		return err
 	}
+	p.resolve(ctx)
 	ctx.StoreCtx().Account.Name = p.Name
 	return nil
 }
@@ -58,5 +77,5 @@ func (p *AccountContextParams) Validate(ctx ActionCtx) error {
 		ctx.CurrentCmd().SilenceUsage = false
 		return errors.New("an account is required")
 	}
-	return nil
+	return p.err
 }
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/cobra"
+)
+
+func createAccountRequestCmd() *cobra.Command {
+	var params AccountRequestParams
+	cmd := &cobra.Command{
+		Use:   "request",
+		Short: "Create a request for the operator to sign an account",
+		Long: `Create a request for the operator to sign an account
+
+The request is the account JWT signed by the account key. Send it to the
+operator of the managed store, and accept the JWT it returns with
+'nsc account accept'.`,
+		Example:      `nsc account request --account A -o A.request.jwt`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			if !IsStdOut(params.out) {
+				cmd.Printf("Success! - wrote the signing request for account %q to %q\n", params.AccountContextParams.Name, params.out)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.out, "output-file", "o", "--", "output file, '--' is stdout")
+	params.AccountContextParams.BindFlags(cmd)
+
+	return cmd
+}
+
+func init() {
+	accountCmd.AddCommand(createAccountRequestCmd())
+}
+
+type AccountRequestParams struct {
+	AccountContextParams
+	SignerParams
+	claim *jwt.AccountClaims
+	token string
+	out   string
+}
+
+func (p *AccountRequestParams) SetDefaults(ctx ActionCtx) error {
+	if !ctx.StoreCtx().Store.IsManaged() {
+		return errors.New("signing requests are for managed operators - the operator of the store signs its accounts")
+	}
+	p.AccountContextParams.SetDefaults(ctx)
+	p.SignerParams.SetDefaults(nkeys.PrefixByteAccount, false, ctx)
+	return nil
+}
+
+func (p *AccountRequestParams) PreInteractive(ctx ActionCtx) error {
+	return p.AccountContextParams.Edit(ctx)
+}
+
+func (p *AccountRequestParams) Load(ctx ActionCtx) error {
+	var err error
+	if err = p.AccountContextParams.Validate(ctx); err != nil {
+		return err
+	}
+	ctx.StoreCtx().Account.Name = p.AccountContextParams.Name
+
+	p.claim, err = ctx.StoreCtx().Store.ReadAccountClaim(p.AccountContextParams.Name)
+	if err != nil {
+		return err
+	}
+	if p.claim == nil {
+		return fmt.Errorf("account %q not found", p.AccountContextParams.Name)
+	}
+	return nil
+}
+
+func (p *AccountRequestParams) PostInteractive(ctx ActionCtx) error {
+	return p.SignerParams.Edit(ctx)
+}
+
+func (p *AccountRequestParams) Validate(ctx ActionCtx) error {
+	if err := p.SignerParams.Resolve(ctx); err != nil {
+		return err
+	}
+	if p.signerKP == nil {
+		return fmt.Errorf("the key of account %q is required to sign the request", p.AccountContextParams.Name)
+	}
+	pub, err := p.signerKP.PublicKey()
+	if err != nil {
+		return err
+	}
+	if pub != p.claim.Subject {
+		return fmt.Errorf("the account key doesn't match account %q", p.AccountContextParams.Name)
+	}
+	return nil
+}
+
+func (p *AccountRequestParams) Run(ctx ActionCtx) error {
+	var err error
+	p.token, err = p.claim.Encode(p.signerKP)
+	if err != nil {
+		return err
+	}
+	return Write(p.out, FormatJwt("Account", p.token))
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AccountRequestRequiresManagedStore(t *testing.T) {
+	ts := NewTestStore(t, "operator")
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	_, _, err := ExecuteCmd(createAccountRequestCmd(), "--account", "A")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signing requests are for managed operators")
+}
+
+func Test_AccountRequest(t *testing.T) {
+	ts := NewTestStoreWithOperator(t, "test", nil)
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	fp := filepath.Join(ts.Dir, "A.request.jwt")
+	_, stderr, err := ExecuteCmd(createAccountRequestCmd(), "--account", "A", "-o", fp)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "wrote the signing request for account \"A\"")
+
+	d, err := ioutil.ReadFile(fp)
+	require.NoError(t, err)
+	ac, err := jwt.DecodeAccountClaims(ExtractToken(string(d)))
+	require.NoError(t, err)
+	require.Equal(t, "A", ac.Name)
+	require.Equal(t, ac.Subject, ac.Issuer)
+}
@@ -17,6 +17,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/nats-io/nsc/cmd/store"
 	"github.com/spf13/cobra"
@@ -31,6 +32,12 @@ type ActionCtx interface {
 
 type ActionFn func(ctx ActionCtx) error
 
+// SelfLocking is implemented by actions that lock the store themselves, like
+// long running servers that only hold the lock while they write
+type SelfLocking interface {
+	LocksStore()
+}
+
 type Action interface {
 	// SetDefaults that can be derived from cmd flags
 	SetDefaults(ctx ActionCtx) error
@@ -100,14 +107,34 @@ func run(ctx ActionCtx, action interface{}) error {
 		}
 	}
 
+	// hold the store while the action reads and writes it, so that concurrent
+	// invocations don't interleave their changes - but not while prompting
+	lock := storeLock(ctx, action)
+	if err := lock.lock(); err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	if InteractiveFlag {
+		lock.watch()
+	}
+
 	if err := e.Load(ctx); err != nil {
 		return err
 	}
 
 	if InteractiveFlag {
+		lock.unlock()
 		if err := e.PostInteractive(ctx); err != nil {
 			return err
 		}
+		if err := lock.lock(); err != nil {
+			return err
+		}
+		// what was loaded is stale if another process changed it while prompting
+		if err := lock.verify(); err != nil {
+			return err
+		}
 	}
 
 	if err := e.Validate(ctx); err != nil {
@@ -121,6 +148,65 @@ func run(ctx ActionCtx, action interface{}) error {
 	return RunInterceptor(ctx, action)
 }
 
+// actionLock is the store lock held by run
+type actionLock struct {
+	s      *store.Store
+	locked bool
+}
+
+// storeLock returns the lock for the store of the action, it doesn't lock
+// anything for actions without a store or that are SelfLocking
+func storeLock(ctx ActionCtx, action interface{}) *actionLock {
+	var l actionLock
+	if _, ok := action.(SelfLocking); ok {
+		return &l
+	}
+	if sctx := ctx.StoreCtx(); sctx != nil {
+		l.s = sctx.Store
+	}
+	return &l
+}
+
+func (l *actionLock) lock() error {
+	if l.s == nil || l.locked {
+		return nil
+	}
+	if err := l.s.LockDir(); err != nil {
+		return err
+	}
+	l.locked = true
+	return nil
+}
+
+func (l *actionLock) unlock() {
+	if l.locked {
+		l.locked = false
+		_ = l.s.UnlockDir()
+	}
+}
+
+// watch records the store files read while the lock is held
+func (l *actionLock) watch() {
+	if l.s != nil {
+		l.s.WatchReads()
+	}
+}
+
+// verify fails if the files read since watch were modified by someone else
+func (l *actionLock) verify() error {
+	if l.s == nil {
+		return nil
+	}
+	changed, err := l.s.ChangedReads()
+	if err != nil {
+		return err
+	}
+	if len(changed) > 0 {
+		return fmt.Errorf("the store was modified by another nsc process while prompting (%s) - run the command again", strings.Join(changed, ", "))
+	}
+	return nil
+}
+
 func (c *Actx) StoreCtx() *store.Context {
 	return c.ctx
 }
@@ -16,8 +16,10 @@
 package cmd
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/nats-io/nsc/cmd/store"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
@@ -200,3 +202,43 @@ func newDefaultAction() actionResponse {
 
 	return ar
 }
+
+func TestActionInteractiveFailsOnConcurrentChange(t *testing.T) {
+	ts := NewTestStore(t, "test")
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	ar := newDefaultAction()
+	ar.load = func(ctx ActionCtx) error {
+		_, err := ctx.StoreCtx().Store.ReadAccountClaim("A")
+		return err
+	}
+	ar.postInteractive = func(ctx ActionCtx) error {
+		// another nsc process updates the account while prompting
+		s, err := store.LoadStore(ctx.StoreCtx().Store.Dir)
+		require.NoError(t, err)
+		ac, err := s.ReadAccountClaim("A")
+		require.NoError(t, err)
+		ac.Tags.Add("changed")
+		token, err := ac.Encode(ts.OperatorKey)
+		require.NoError(t, err)
+		return s.StoreClaim([]byte(token))
+	}
+	ran := false
+	ar.run = func(ctx ActionCtx) error {
+		ran = true
+		return nil
+	}
+
+	cmd := &cobra.Command{
+		Use: "edit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunAction(cmd, args, &ar)
+		},
+	}
+	_, _, err := ExecuteInteractiveCmd(cmd, []interface{}{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "modified by another nsc process")
+	require.Contains(t, err.Error(), filepath.Join(store.Accounts, "A", "A.jwt"))
+	require.False(t, ran)
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+)
+
+// cached activations younger than this are used without contacting the server
+const activationCacheMaxAge = time.Hour
+
+// ActivationCache resolves activation tokens referenced by url, keeping a copy
+// of the tokens in the store so they are available offline
+type ActivationCache struct {
+	Store   *store.Store
+	Offline bool
+	MaxAge  time.Duration
+	Timeout time.Duration
+}
+
+// FetchResult is the outcome of downloading an activation
+type FetchResult struct {
+	Token   string
+	Changed bool
+}
+
+func NewActivationCache(s *store.Store, offline bool) *ActivationCache {
+	return &ActivationCache{Store: s, Offline: offline, MaxAge: activationCacheMaxAge, Timeout: 5 * time.Second}
+}
+
+// Load returns the activation token for the url. Recently cached tokens are returned
+// without a request, and if the server cannot be reached the cached token is used.
+func (c *ActivationCache) Load(url string) (string, error) {
+	cached, err := c.Store.ReadCachedActivation(url)
+	if err != nil {
+		return "", err
+	}
+	if c.Offline {
+		if cached == nil {
+			return "", fmt.Errorf("activation %q is not cached", url)
+		}
+		return cached.Token, nil
+	}
+	if cached != nil && time.Since(time.Unix(cached.Fetched, 0)) < c.MaxAge {
+		return cached.Token, nil
+	}
+	r, err := c.Fetch(url)
+	if err != nil {
+		if cached != nil {
+			return cached.Token, nil
+		}
+		return "", err
+	}
+	return r.Token, nil
+}
+
+// Fetch downloads the activation, validating the cached copy using its ETag and JTI
+func (c *ActivationCache) Fetch(url string) (*FetchResult, error) {
+	cached, err := c.Store.ReadCachedActivation(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error loading %q: %v", url, err)
+	}
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	hc := &http.Client{Timeout: c.Timeout}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error loading %q: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		cached.Fetched = time.Now().Unix()
+		if err := c.Store.CacheActivation(cached); err != nil {
+			return nil, err
+		}
+		return &FetchResult{Token: cached.Token}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error loading %q: %s", url, resp.Status)
+	}
+
+	d, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from %q: %v", url, err)
+	}
+	token := strings.TrimSpace(ExtractToken(string(d)))
+	ac, err := jwt.DecodeActivationClaims(token)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding activation from %q: %v", url, err)
+	}
+
+	r := &FetchResult{Token: token, Changed: cached == nil || cached.ID != ac.ID}
+	err = c.Store.CacheActivation(&store.CachedActivation{
+		URL:     url,
+		ETag:    resp.Header.Get("ETag"),
+		ID:      ac.ID,
+		Token:   token,
+		Fetched: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+// ActivationServer serves an activation token with an ETag
+type ActivationServer struct {
+	sync.Mutex
+	*httptest.Server
+	token    string
+	requests int
+	notMod   int
+}
+
+func NewActivationServer(token string) *ActivationServer {
+	var as ActivationServer
+	as.token = token
+	as.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		as.Lock()
+		defer as.Unlock()
+		as.requests++
+		ac, _ := jwt.DecodeActivationClaims(as.token)
+		etag := `"` + ac.ID + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			as.notMod++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(as.token))
+	}))
+	return &as
+}
+
+func (as *ActivationServer) SetToken(token string) {
+	as.Lock()
+	defer as.Unlock()
+	as.token = token
+}
+
+func (as *ActivationServer) Requests() (int, int) {
+	as.Lock()
+	defer as.Unlock()
+	return as.requests, as.notMod
+}
+
+// signActivation creates an activation for the export without parsing command output
+func signActivation(t *testing.T, ts *TestStore, account string, subject string, target string) string {
+	tpub, err := ts.KeyStore.GetAccountPublicKey(target)
+	require.NoError(t, err)
+	ac := jwt.NewActivationClaims(tpub)
+	ac.ImportSubject = jwt.Subject(subject)
+	ac.ImportType = jwt.Stream
+	kp, err := ts.KeyStore.GetAccountKey(account)
+	require.NoError(t, err)
+	token, err := ac.Encode(kp)
+	require.NoError(t, err)
+	return token
+}
+
+// reissueActivation returns a copy of the activation with a different expiration
+func reissueActivation(t *testing.T, ts *TestStore, account string, token string) string {
+	ac, err := jwt.DecodeActivationClaims(token)
+	require.NoError(t, err)
+	ac.Expires = time.Now().AddDate(0, 0, 1).Unix()
+	kp, err := ts.KeyStore.GetAccountKey(account)
+	require.NoError(t, err)
+	token, err = ac.Encode(kp)
+	require.NoError(t, err)
+	return token
+}
+
+func Test_ActivationCache(t *testing.T) {
+	ts := NewTestStore(t, "cache")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo", false)
+	ts.AddAccount(t, "B")
+	as := NewActivationServer(signActivation(t, ts, "A", "foo", "B"))
+	defer as.Close()
+
+	cache := NewActivationCache(ts.Store, true)
+	_, err := cache.Load(as.URL)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not cached")
+
+	cache.Offline = false
+	token, err := cache.Load(as.URL)
+	require.NoError(t, err)
+	require.Equal(t, as.token, token)
+
+	// served from the cache
+	_, err = cache.Load(as.URL)
+	require.NoError(t, err)
+	requests, _ := as.Requests()
+	require.Equal(t, 1, requests)
+
+	// validated with the etag
+	r, err := cache.Fetch(as.URL)
+	require.NoError(t, err)
+	require.False(t, r.Changed)
+	_, notMod := as.Requests()
+	require.Equal(t, 1, notMod)
+
+	as.SetToken(reissueActivation(t, ts, "A", as.token))
+	r, err = cache.Fetch(as.URL)
+	require.NoError(t, err)
+	require.True(t, r.Changed)
+	require.Equal(t, as.token, r.Token)
+
+	// cached tokens are used when the server is not available
+	as.Close()
+	cache.MaxAge = 0
+	token, err = cache.Load(as.URL)
+	require.NoError(t, err)
+	require.Equal(t, r.Token, token)
+}
+
+func Test_DescribeAccountOffline(t *testing.T) {
+	ts := NewTestStore(t, "cache")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo", false)
+	ts.AddAccount(t, "B")
+	as := NewActivationServer(signActivation(t, ts, "A", "foo", "B"))
+
+	_, _, err := ExecuteCmd(createAddImportCmd(), "--account", "B", "--token", as.URL)
+	require.NoError(t, err)
+
+	stdout, _, err := ExecuteCmd(createDescribeAccountCmd(), "--account", "B", "--offline")
+	require.NoError(t, err)
+	require.Contains(t, stdout, "is not cached")
+
+	_, _, err = ExecuteCmd(createDescribeAccountCmd(), "--account", "B")
+	require.NoError(t, err)
+	as.Close()
+
+	stdout, _, err = ExecuteCmd(createDescribeAccountCmd(), "--account", "B", "--offline")
+	require.NoError(t, err)
+	require.NotContains(t, stdout, "error decoding")
+}
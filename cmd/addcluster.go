@@ -27,9 +27,10 @@ import (
 func createAddClusterCmd() *cobra.Command {
 	var params AddClusterParams
 	cmd := &cobra.Command{
-		Use:          "cluster",
-		Short:        "Add a cluster (operator only)",
-		Example:      `nsc add cluster --name mycluster --trusted-accounts actkey1,actkey2 --trusted-operators opkey1,opkey2`,
+		Use:   "cluster",
+		Short: "Add a cluster (operator only)",
+		Example: `nsc add cluster --name mycluster --trusted-accounts actkey1,actkey2 --trusted-operators opkey1,opkey2
+nsc add cluster --name mycluster --trusted-accounts A,B --trusted-operators O`,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := RunAction(cmd, args, &params); err != nil {
@@ -45,8 +46,8 @@ func createAddClusterCmd() *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&params.name, "name", "n", "", "cluster name")
 	cmd.Flags().StringVarP(&params.keyPath, "public-key", "k", "", "public key identifying the cluster")
-	cmd.Flags().StringSliceVar(&params.accounts, "trusted-accounts", nil, "trusted account public keys")
-	cmd.Flags().StringSliceVar(&params.operators, "trusted-operators", nil, "trusted operator public keys")
+	cmd.Flags().StringSliceVar(&params.accounts, "trusted-accounts", nil, "trusted account names or public keys")
+	cmd.Flags().StringSliceVar(&params.operators, "trusted-operators", nil, "trusted operator names or public keys")
 	cmd.Flags().StringVar(&params.accountUrlTemplate, "account-url-template", "", "template url for retrieving account jwts by account id")
 	cmd.Flags().StringVar(&params.operatorUrlTemplate, "operator-url-template", "", "template url for retrieving operator jwts by operator id")
 	params.TimeParams.BindFlags(cmd)
@@ -123,16 +124,12 @@ func (p *AddClusterParams) Validate(ctx ActionCtx) error {
 		return err
 	}
 
-	for _, v := range p.accounts {
-		if !nkeys.IsValidPublicAccountKey(v) {
-			return fmt.Errorf("%q is not a valid account public key", v)
-		}
+	if p.accounts, err = resolveTrustedAccounts(ctx.StoreCtx().Store, p.accounts); err != nil {
+		return err
 	}
 
-	for _, v := range p.operators {
-		if !nkeys.IsValidPublicOperatorKey(v) {
-			return fmt.Errorf("%q is not a valid operator public key", v)
-		}
+	if p.operators, err = resolveTrustedOperators(p.operators); err != nil {
+		return err
 	}
 
 	return p.Valid()
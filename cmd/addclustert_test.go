@@ -107,3 +107,21 @@ func validateClusterClaims(t *testing.T, ts *TestStore) {
 	require.NoError(t, err)
 	require.Equal(t, expire, ac.Expires)
 }
+
+func Test_AddClusterTrustedNames(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	_, _, err := ExecuteCmd(createAddClusterCmd(), "--name", "C", "--trusted-accounts", "A", "--trusted-operators", "O")
+	require.NoError(t, err)
+
+	cc, err := ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	apk, err := ts.KeyStore.GetAccountPublicKey("A")
+	require.NoError(t, err)
+	opk, err := ts.OperatorKey.PublicKey()
+	require.NoError(t, err)
+	require.Equal(t, []string{apk}, cc.Accounts)
+	require.Equal(t, []string{opk}, cc.Trust)
+}
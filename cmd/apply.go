@@ -0,0 +1,894 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cli"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+const (
+	ApplyCreate = "create"
+	ApplyUpdate = "update"
+	ApplyDelete = "delete"
+)
+
+func createApplyCmd() *cobra.Command {
+	var params ApplyParams
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create or update the operators, accounts, users, clusters and servers described by a spec",
+		Long: `Create or update the operators, accounts, users, clusters and servers described by a spec
+
+The spec is a YAML or JSON file. The changes needed to make the store match
+the spec are shown and then applied using the same rules as the add, edit and
+delete commands. Applying a spec that matches the store does nothing.
+
+Entities in the store that are not in the spec are left alone. The tags,
+limits, exports, imports, permissions and trust of the entities in the spec
+are made to match the spec. Keys are generated unless the spec has a
+public_key for the entity, and seeds are read from the keystore.
+
+operators:
+- name: O
+  accounts:
+  - name: A
+    tags: [prod]
+    limits:
+      conns: 100
+    exports:
+    - subject: a.>
+      private: true
+    - subject: help
+      type: service
+  - name: B
+    imports:
+    - account: A
+      subject: a.>
+      to: from_a
+    users:
+    - name: u
+      allow_pub: [">"]
+      deny_sub: [private.>]
+      source_networks: [192.168.1.0/24]
+  clusters:
+  - name: C
+    account_url_template: http://localhost:9090/jwt/v1/accounts/
+    trusted_accounts: [A, B]
+    servers:
+    - name: s1`,
+		Example: `nsc apply -f spec.yaml
+nsc apply -f spec.json --dry-run`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunStoreLessAction(cmd, args, &params); err != nil {
+				return err
+			}
+			switch {
+			case len(params.plan) == 0:
+				cmd.Println("No changes - the store matches the spec")
+			case params.dryRun:
+				cmd.Printf("Dry run - %d change(s) not applied\n", len(params.plan))
+			default:
+				cmd.Printf("Success! - applied %d change(s)\n", len(params.plan))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.file, "file", "f", "", "YAML or JSON spec file")
+	cmd.Flags().BoolVarP(&params.dryRun, "dry-run", "", false, "only show the changes")
+
+	return cmd
+}
+
+func init() {
+	GetRootCmd().AddCommand(createApplyCmd())
+}
+
+// ApplyStep is a change to an entity. Steps are run by the actions of the
+// equivalent commands, so the rules are the same as on the command line.
+type ApplyStep struct {
+	Operator string
+	Op       string
+	Kind     string
+	Name     string
+	Changes  []string
+	run      []func() error
+}
+
+type ApplyParams struct {
+	file   string
+	dryRun bool
+	root   string
+	spec   *Spec
+	plan   []*ApplyStep
+}
+
+func (p *ApplyParams) SetDefaults(ctx ActionCtx) error {
+	if p.file == "" {
+		ctx.CurrentCmd().SilenceUsage = false
+		return errors.New("a spec file is required")
+	}
+	return nil
+}
+
+func (p *ApplyParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+// Load reads the spec and computes the plan against the store
+func (p *ApplyParams) Load(ctx ActionCtx) error {
+	var err error
+	p.spec, err = LoadSpec(p.file)
+	if err != nil {
+		return err
+	}
+	return p.planSpec()
+}
+
+// planSpec computes the changes that converge the stores directory to the spec
+func (p *ApplyParams) planSpec() error {
+	p.root = GetConfig().StoreRoot
+	if p.root == "" {
+		return errors.New("no stores directory - set one with `env --store`")
+	}
+	if err := IsValidDir(p.root); err != nil {
+		return fmt.Errorf("error loading stores directory %q: %v", p.root, err)
+	}
+	for _, o := range p.spec.Operators {
+		if err := p.planOperator(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ApplyParams) PostInteractive(ctx ActionCtx) error {
+	if len(p.plan) == 0 || p.dryRun {
+		return nil
+	}
+	ctx.CurrentCmd().Println(p.PlanTable())
+	ok, err := cli.PromptBoolean("apply the changes", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("cancelled")
+	}
+	return nil
+}
+
+func (p *ApplyParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ApplyParams) Run(ctx ActionCtx) error {
+	if len(p.plan) == 0 {
+		return nil
+	}
+	if !InteractiveFlag || p.dryRun {
+		ctx.CurrentCmd().Println(p.PlanTable())
+	}
+	if p.dryRun {
+		return nil
+	}
+
+	// the steps run the actions non-interactively in the context of the entity
+	// they change, with keys from the keystore - restore the settings when done
+	interactive, keyPath := InteractiveFlag, KeyPathFlag
+	saved, current := savedContext, GetConfig().ContextConfig
+	defer func() {
+		InteractiveFlag, KeyPathFlag = interactive, keyPath
+		savedContext, GetConfig().ContextConfig = saved, current
+	}()
+	InteractiveFlag, KeyPathFlag = false, ""
+
+	for _, s := range p.plan {
+		for _, fn := range s.run {
+			if err := fn(); err != nil {
+				return fmt.Errorf("error applying %s %s %q: %v", s.Op, s.Kind, s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// PlanTable renders the changes
+func (p *ApplyParams) PlanTable() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle("Changes")
+	table.AddHeaders("Operator", "Action", "Kind", "Name", "Changes")
+	for _, s := range p.plan {
+		table.AddRow(s.Operator, s.Op, s.Kind, s.Name, strings.Join(s.Changes, "; "))
+	}
+	return table.Render()
+}
+
+func (p *ApplyParams) add(s *ApplyStep) {
+	if len(s.run) > 0 {
+		p.plan = append(p.plan, s)
+	}
+}
+
+// runAction runs an action with the command that normally drives it, the
+// named flags are marked as changed as if they were set on the command line
+func runAction(c ContextConfig, cmd *cobra.Command, action Action, changed ...string) error {
+	GetConfig().override(c)
+	for _, n := range changed {
+		cmd.Flag(n).Changed = true
+	}
+	ctx, err := NewActx(cmd, nil)
+	if err != nil {
+		return err
+	}
+	return run(ctx, action)
+}
+
+func (p *ApplyParams) loadStore(operator string) (*store.Store, error) {
+	dir := filepath.Join(p.root, operator)
+	if _, err := os.Stat(filepath.Join(dir, store.NSCFile)); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return store.LoadStore(dir)
+}
+
+func (p *ApplyParams) planOperator(o *OperatorSpec) error {
+	s, err := p.loadStore(o.Name)
+	if err != nil {
+		return err
+	}
+	c := ContextConfig{StoreRoot: p.root, Operator: o.Name}
+	if s == nil {
+		p.add(&ApplyStep{Operator: o.Name, Op: ApplyCreate, Kind: "operator", Name: o.Name,
+			run: []func() error{func() error {
+				return createOperator(c, o.PublicKey)
+			}}})
+	} else {
+		pk, err := s.GetRootPublicKey()
+		if err != nil {
+			return err
+		}
+		if err := checkPublicKey("operator", o.Name, pk, o.PublicKey); err != nil {
+			return err
+		}
+	}
+
+	// imports need the exports of the other accounts, users need the imports
+	for _, a := range o.Accounts {
+		if err := p.planAccount(s, c, a); err != nil {
+			return err
+		}
+	}
+	for _, a := range o.Accounts {
+		if err := p.planImports(s, c, a); err != nil {
+			return err
+		}
+	}
+	for _, a := range o.Accounts {
+		for _, u := range a.Users {
+			if err := p.planUser(s, c, a.Name, u); err != nil {
+				return err
+			}
+		}
+	}
+	for _, cs := range o.Clusters {
+		if err := p.planCluster(s, c, cs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createOperator creates the operator store like init does. The operator
+// signs its own jwt, so an operator with a public key requires its seed in
+// the keystore.
+func createOperator(c ContextConfig, pubkey string) error {
+	ks := store.NewKeyStore(c.Operator)
+	kp, err := ks.GetOperatorKey(c.Operator)
+	if err != nil {
+		return err
+	}
+	switch {
+	case pubkey != "":
+		if kp == nil || !store.Match(pubkey, kp) {
+			return fmt.Errorf("the seed for operator key %q is not in the keystore", pubkey)
+		}
+	case kp == nil:
+		if kp, err = nkeys.CreateOperator(); err != nil {
+			return err
+		}
+		if _, err = ks.Store(c.Operator, kp, ""); err != nil {
+			return err
+		}
+	}
+	_, err = store.CreateStore(c.Operator, c.StoreRoot, &store.NamedKey{Name: c.Operator, KP: kp})
+	return err
+}
+
+// checkPublicKey verifies that an existing entity has the public key in the spec
+func checkPublicKey(kind string, name string, current string, desired string) error {
+	if desired != "" && current != desired {
+		return fmt.Errorf("%s %q has public key %q, the spec requires %q", kind, name, current, desired)
+	}
+	return nil
+}
+
+func (p *ApplyParams) planAccount(s *store.Store, c ContextConfig, a *AccountSpec) error {
+	c.Account = a.Name
+	var ac *jwt.AccountClaims
+	if s != nil {
+		var err error
+		if ac, err = s.ReadAccountClaim(a.Name); err != nil {
+			return err
+		}
+	}
+
+	step := &ApplyStep{Operator: c.Operator, Op: ApplyUpdate, Kind: "account", Name: a.Name}
+	if ac != nil {
+		if err := checkPublicKey("account", a.Name, ac.Subject, a.PublicKey); err != nil {
+			return err
+		}
+	} else {
+		step.Op = ApplyCreate
+		ac = &jwt.AccountClaims{}
+		step.run = append(step.run, func() error {
+			params := &AddAccountParams{}
+			params.name = a.Name
+			params.keyPath = a.PublicKey
+			return runAction(c, CreateAddAccountCmd(), params)
+		})
+	}
+
+	params := &EditAccountParams{}
+	params.AccountContextParams.Name = a.Name
+	var changed []string
+	params.tags, params.rmTags = diffTags(ac.Tags, a.Tags)
+	if len(params.tags) > 0 || len(params.rmTags) > 0 {
+		step.Changes = append(step.Changes, describeDiff("tags", params.tags, params.rmTags))
+		changed = append(changed, "tag", "rm-tag")
+	}
+	if a.Limits.Conns > 0 && a.Limits.Conns != ac.Limits.Conn {
+		params.conns.NumberValue = a.Limits.Conns
+		step.Changes = append(step.Changes, fmt.Sprintf("conns %d", a.Limits.Conns))
+		changed = append(changed, "conns")
+	}
+	if len(changed) > 0 {
+		step.run = append(step.run, func() error {
+			return runAction(c, createEditAccount(), params, changed...)
+		})
+	}
+	p.add(step)
+
+	return p.planExports(c, ac, a)
+}
+
+func (p *ApplyParams) planExports(c ContextConfig, ac *jwt.AccountClaims, a *AccountSpec) error {
+	desired := make(map[string]*jwt.Export)
+	for _, e := range a.Exports {
+		kind, _ := e.ExportType()
+		name := e.Name
+		if name == "" {
+			name = e.Subject
+		}
+		desired[e.Subject] = &jwt.Export{Name: name, Subject: jwt.Subject(e.Subject), Type: kind, TokenReq: e.Private}
+	}
+
+	deleteExport := func(subject string) func() error {
+		return func() error {
+			params := &DeleteExportParams{}
+			params.AccountContextParams.Name = a.Name
+			params.subject = subject
+			return runAction(c, createDeleteExportCmd(), params)
+		}
+	}
+
+	current := make(map[string]*jwt.Export)
+	for _, e := range ac.Exports {
+		current[string(e.Subject)] = e
+		if _, ok := desired[string(e.Subject)]; !ok {
+			p.add(&ApplyStep{Operator: c.Operator, Op: ApplyDelete, Kind: "export", Name: childName(a.Name, string(e.Subject)),
+				run: []func() error{deleteExport(string(e.Subject))}})
+		}
+	}
+
+	for _, e := range a.Exports {
+		d := desired[e.Subject]
+		step := &ApplyStep{Operator: c.Operator, Op: ApplyCreate, Kind: "export", Name: childName(a.Name, e.Subject),
+			Changes: []string{describeExport(d)}}
+		if cur, ok := current[e.Subject]; ok {
+			if cur.Name == d.Name && cur.Type == d.Type && cur.TokenReq == d.TokenReq {
+				continue
+			}
+			step.Op = ApplyUpdate
+			step.run = append(step.run, deleteExport(e.Subject))
+		}
+		step.run = append(step.run, func() error {
+			params := &AddExportParams{}
+			params.AccountContextParams.Name = a.Name
+			params.export.Name = d.Name
+			params.subject = string(d.Subject)
+			params.service = d.IsService()
+			params.private = d.TokenReq
+			return runAction(c, createAddExportCmd(), params)
+		})
+		p.add(step)
+	}
+	return nil
+}
+
+func (p *ApplyParams) planImports(s *store.Store, c ContextConfig, a *AccountSpec) error {
+	c.Account = a.Name
+	var ac *jwt.AccountClaims
+	if s != nil {
+		var err error
+		if ac, err = s.ReadAccountClaim(a.Name); err != nil {
+			return err
+		}
+	}
+	if ac == nil {
+		ac = &jwt.AccountClaims{}
+	}
+
+	deleteImport := func(subject string) func() error {
+		return func() error {
+			params := &DeleteImportParams{}
+			params.AccountContextParams.Name = a.Name
+			params.subject = subject
+			return runAction(c, createDeleteImportCmd(), params)
+		}
+	}
+
+	matched := make(map[*jwt.Import]bool)
+	for _, im := range a.Imports {
+		// the exporting account and subject identify the import
+		issuer, subject, token := "", im.Subject, ""
+		if im.Token != "" {
+			// the token is inline, as written by export spec, or in a file or url
+			d := []byte(im.Token)
+			act, err := jwt.DecodeActivationClaims(im.Token)
+			if err != nil {
+				if d, err = (&AddImportParams{src: im.Token}).LoadImport(); err != nil {
+					return err
+				}
+				if act, err = jwt.DecodeActivationClaims(string(d)); err != nil {
+					return fmt.Errorf("error decoding activation %q: %v", im.Token, err)
+				}
+			}
+			issuer, subject, token = act.Issuer, string(act.Activation.ImportSubject), string(d)
+		} else if s != nil {
+			src, err := s.ReadAccountClaim(im.Account)
+			if err != nil {
+				return err
+			}
+			if src != nil {
+				issuer = src.Subject
+			}
+		}
+
+		from := im.Account
+		if from == "" {
+			from = issuer
+		}
+		step := &ApplyStep{Operator: c.Operator, Op: ApplyCreate, Kind: "import", Name: childName(a.Name, subject),
+			Changes: []string{fmt.Sprintf("from %s", from)}}
+		if im.To != "" {
+			step.Changes = append(step.Changes, fmt.Sprintf("to %s", im.To))
+		}
+
+		var cur *jwt.Import
+		for _, v := range ac.Imports {
+			if issuer != "" && v.Account == issuer && string(v.Subject) == subject {
+				cur = v
+				break
+			}
+		}
+		if cur != nil {
+			matched[cur] = true
+			if string(cur.To) == im.To && (im.Name == "" || cur.Name == im.Name) {
+				continue
+			}
+			step.Op = ApplyUpdate
+			step.run = append(step.run, deleteImport(subject))
+		}
+
+		step.run = append(step.run, func() error {
+			t := token
+			if t == "" {
+				var err error
+				if t, err = generateActivation(c, im.Account, im.Subject, a.Name); err != nil {
+					return err
+				}
+			}
+			params := &AddImportParams{}
+			params.AccountContextParams.Name = a.Name
+			params.im.Name = im.Name
+			params.to = im.To
+			params.src = t
+			params.token = []byte(t)
+			return runAction(c, createAddImportCmd(), params)
+		})
+		p.add(step)
+	}
+
+	for _, v := range ac.Imports {
+		if !matched[v] {
+			p.add(&ApplyStep{Operator: c.Operator, Op: ApplyDelete, Kind: "import", Name: childName(a.Name, string(v.Subject)),
+				run: []func() error{deleteImport(string(v.Subject))}})
+		}
+	}
+	return nil
+}
+
+// generateActivation generates an activation for a private export of an account in the store
+func generateActivation(c ContextConfig, exporter string, subject string, importer string) (string, error) {
+	s, err := store.LoadStore(filepath.Join(c.StoreRoot, c.Operator))
+	if err != nil {
+		return "", err
+	}
+	ac, err := s.ReadAccountClaim(importer)
+	if err != nil {
+		return "", err
+	}
+	if ac == nil {
+		return "", fmt.Errorf("account %q is not in the store", importer)
+	}
+
+	c.Account = exporter
+	params := &GenerateActivationParams{}
+	params.AccountContextParams.Name = exporter
+	params.subject = subject
+	params.targetKey.flagName = "target-account"
+	params.targetKey.kind = nkeys.PrefixByteAccount
+	params.targetKey.path = ac.Subject
+	if err := runAction(c, createGenerateActivationCmd(), params); err != nil {
+		return "", err
+	}
+	return params.Token, nil
+}
+
+func (p *ApplyParams) planUser(s *store.Store, c ContextConfig, account string, u *UserSpec) error {
+	c.Account = account
+	var uc *jwt.UserClaims
+	if s != nil {
+		var err error
+		if uc, err = s.ReadUserClaim(account, u.Name); err != nil {
+			return err
+		}
+	}
+
+	step := &ApplyStep{Operator: c.Operator, Op: ApplyUpdate, Kind: "user", Name: childName(account, u.Name)}
+	if uc != nil {
+		if err := checkPublicKey("user", childName(account, u.Name), uc.Subject, u.PublicKey); err != nil {
+			return err
+		}
+	} else {
+		step.Op = ApplyCreate
+		uc = &jwt.UserClaims{}
+		step.run = append(step.run, func() error {
+			params := &AddUserParams{}
+			params.AccountContextParams.Name = account
+			params.name = u.Name
+			params.keyPath = u.PublicKey
+			return runAction(c, CreateAddUserCmd(), params)
+		})
+	}
+
+	// edit user removes subjects from all the permission lists, so the
+	// subjects removed from one list are added back to the lists keeping them
+	current := [][]string{uc.Pub.Allow, uc.Sub.Allow, uc.Pub.Deny, uc.Sub.Deny}
+	desired := [][]string{u.AllowPub, u.AllowSub, u.DenyPub, u.DenySub}
+	labels := []string{"allow_pub", "allow_sub", "deny_pub", "deny_sub"}
+	var remove jwt.StringList
+	adds := make([][]string, len(current))
+	for i := range current {
+		add, rm := diffList(current[i], desired[i])
+		if len(add) > 0 || len(rm) > 0 {
+			step.Changes = append(step.Changes, describeDiff(labels[i], add, rm))
+		}
+		remove.Add(rm...)
+	}
+	for i := range current {
+		var kept jwt.StringList
+		kept.Add(current[i]...)
+		kept.Remove(remove...)
+		adds[i], _ = diffList(kept, desired[i])
+	}
+	if len(remove) > 0 {
+		step.run = append(step.run, func() error {
+			params := &EditUserParams{}
+			params.AccountContextParams.Name = account
+			params.name = u.Name
+			params.remove = remove
+			return runAction(c, createEditUserCmd(), params, "rm")
+		})
+	}
+
+	params := &EditUserParams{}
+	params.AccountContextParams.Name = account
+	params.name = u.Name
+	params.allowPubs, params.allowSubs, params.denyPubs, params.denySubs = adds[0], adds[1], adds[2], adds[3]
+	var changed []string
+	for i, n := range []string{"allow-pub", "allow-sub", "deny-pub", "deny-sub"} {
+		if len(adds[i]) > 0 {
+			changed = append(changed, n)
+		}
+	}
+	var src []string
+	for _, v := range strings.Split(uc.Src, ",") {
+		if v != "" {
+			src = append(src, v)
+		}
+	}
+	params.src, params.rmSrc = diffList(src, u.SourceNetworks)
+	if len(params.src) > 0 || len(params.rmSrc) > 0 {
+		step.Changes = append(step.Changes, describeDiff("source_networks", params.src, params.rmSrc))
+		changed = append(changed, "source-network", "rm-source-network")
+	}
+	params.tags, params.rmTags = diffTags(uc.Tags, u.Tags)
+	if len(params.tags) > 0 || len(params.rmTags) > 0 {
+		step.Changes = append(step.Changes, describeDiff("tags", params.tags, params.rmTags))
+		changed = append(changed, "tag", "rm-tag")
+	}
+	if len(changed) > 0 {
+		step.run = append(step.run, func() error {
+			return runAction(c, createEditUserCmd(), params, changed...)
+		})
+	}
+	p.add(step)
+	return nil
+}
+
+func (p *ApplyParams) planCluster(s *store.Store, c ContextConfig, cs *ClusterSpec) error {
+	c.Cluster = cs.Name
+	var cc *jwt.ClusterClaims
+	if s != nil {
+		var err error
+		if cc, err = s.ReadClusterClaim(cs.Name); err != nil {
+			return err
+		}
+	}
+
+	step := &ApplyStep{Operator: c.Operator, Op: ApplyUpdate, Kind: "cluster", Name: cs.Name}
+	if cc != nil {
+		if err := checkPublicKey("cluster", cs.Name, cc.Subject, cs.PublicKey); err != nil {
+			return err
+		}
+	} else {
+		step.Op = ApplyCreate
+		cc = &jwt.ClusterClaims{}
+		step.run = append(step.run, func() error {
+			params := &AddClusterParams{}
+			params.name = cs.Name
+			params.keyPath = cs.PublicKey
+			return runAction(c, createAddClusterCmd(), params)
+		})
+	}
+
+	var changed []string
+	if cc.AccountURL != cs.AccountURLTemplate {
+		step.Changes = append(step.Changes, fmt.Sprintf("account_url_template %s", cs.AccountURLTemplate))
+		changed = append(changed, "account-url-template")
+	}
+	if cc.OperatorURL != cs.OperatorURLTemplate {
+		step.Changes = append(step.Changes, fmt.Sprintf("operator_url_template %s", cs.OperatorURLTemplate))
+		changed = append(changed, "operator-url-template")
+	}
+	// names that can't be resolved are for entities that the plan creates
+	accounts, err := p.resolveAccounts(s, cs.TrustedAccounts)
+	if err != nil || !sameSet(cc.Accounts, accounts) {
+		step.Changes = append(step.Changes, fmt.Sprintf("trusted_accounts %s", strings.Join(cs.TrustedAccounts, ",")))
+		changed = append(changed, "trusted-accounts")
+	}
+	operators, err := p.resolveOperators(cs.TrustedOperators)
+	if err != nil || !sameSet(cc.Trust, operators) {
+		step.Changes = append(step.Changes, fmt.Sprintf("trusted_operators %s", strings.Join(cs.TrustedOperators, ",")))
+		changed = append(changed, "trusted-operators")
+	}
+	tags, rmTags := diffTags(cc.Tags, cs.Tags)
+	if len(tags) > 0 || len(rmTags) > 0 {
+		step.Changes = append(step.Changes, describeDiff("tags", tags, rmTags))
+		changed = append(changed, "tag", "rm-tag")
+	}
+	if len(changed) > 0 {
+		step.run = append(step.run, func() error {
+			s, err := store.LoadStore(filepath.Join(c.StoreRoot, c.Operator))
+			if err != nil {
+				return err
+			}
+			params := &EditClusterParams{}
+			params.ClusterContextParams.Name = cs.Name
+			params.accountUrlTemplate = cs.AccountURLTemplate
+			params.operatorUrlTemplate = cs.OperatorURLTemplate
+			if params.accounts, err = p.resolveAccounts(s, cs.TrustedAccounts); err != nil {
+				return err
+			}
+			if params.operators, err = p.resolveOperators(cs.TrustedOperators); err != nil {
+				return err
+			}
+			params.tags, params.rmTags = tags, rmTags
+			return runAction(c, createEditClusterCmd(), params, changed...)
+		})
+	}
+	p.add(step)
+
+	for _, sv := range cs.Servers {
+		if err := p.planServer(s, c, sv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ApplyParams) planServer(s *store.Store, c ContextConfig, sv *ServerSpec) error {
+	var sc *jwt.ServerClaims
+	if s != nil {
+		var err error
+		if sc, err = s.ReadServerClaim(c.Cluster, sv.Name); err != nil {
+			return err
+		}
+	}
+
+	step := &ApplyStep{Operator: c.Operator, Op: ApplyUpdate, Kind: "server", Name: childName(c.Cluster, sv.Name)}
+	if sc != nil {
+		if err := checkPublicKey("server", childName(c.Cluster, sv.Name), sc.Subject, sv.PublicKey); err != nil {
+			return err
+		}
+	} else {
+		step.Op = ApplyCreate
+		sc = &jwt.ServerClaims{}
+		step.run = append(step.run, func() error {
+			params := &AddServerParams{}
+			params.ClusterContextParams.Name = c.Cluster
+			params.name = sv.Name
+			params.keyPath = sv.PublicKey
+			return runAction(c, createAddServerCmd(), params)
+		})
+	}
+
+	tags, rmTags := diffTags(sc.Tags, sv.Tags)
+	if len(tags) > 0 || len(rmTags) > 0 {
+		step.Changes = append(step.Changes, describeDiff("tags", tags, rmTags))
+		step.run = append(step.run, func() error {
+			params := &EditServerParams{}
+			params.ClusterContextParams.Name = c.Cluster
+			params.name = sv.Name
+			params.tags, params.rmTags = tags, rmTags
+			return runAction(c, createEditServerCmd(), params, "tag", "rm-tag")
+		})
+	}
+	p.add(step)
+	return nil
+}
+
+// resolveAccounts returns the public keys of the named accounts
+func (p *ApplyParams) resolveAccounts(s *store.Store, names []string) ([]string, error) {
+	var keys []string
+	for _, n := range names {
+		if nkeys.IsValidPublicAccountKey(n) {
+			keys = append(keys, n)
+			continue
+		}
+		var ac *jwt.AccountClaims
+		if s != nil {
+			var err error
+			if ac, err = s.ReadAccountClaim(n); err != nil {
+				return nil, err
+			}
+		}
+		if ac == nil {
+			return nil, fmt.Errorf("account %q is not in the store", n)
+		}
+		keys = append(keys, ac.Subject)
+	}
+	return keys, nil
+}
+
+// resolveOperators returns the public keys of the named operators in the stores directory
+func (p *ApplyParams) resolveOperators(names []string) ([]string, error) {
+	var keys []string
+	for _, n := range names {
+		if nkeys.IsValidPublicOperatorKey(n) {
+			keys = append(keys, n)
+			continue
+		}
+		s, err := p.loadStore(n)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			return nil, fmt.Errorf("operator %q is not in %q", n, p.root)
+		}
+		pk, err := s.GetRootPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, pk)
+	}
+	return keys, nil
+}
+
+// diffList returns the values to add and to remove to turn current into desired
+func diffList(current []string, desired []string) ([]string, []string) {
+	var c, d jwt.StringList
+	c.Add(current...)
+	d.Add(desired...)
+	var add, rm []string
+	for _, v := range d {
+		if !c.Contains(v) {
+			add = append(add, v)
+		}
+	}
+	for _, v := range c {
+		if !d.Contains(v) {
+			rm = append(rm, v)
+		}
+	}
+	return add, rm
+}
+
+// diffTags is diffList for tags, which are lower case
+func diffTags(current jwt.TagList, desired []string) ([]string, []string) {
+	var d jwt.TagList
+	d.Add(desired...)
+	return diffList(current, d)
+}
+
+func sameSet(a []string, b []string) bool {
+	add, rm := diffList(a, b)
+	return len(add) == 0 && len(rm) == 0
+}
+
+func describeDiff(label string, add []string, rm []string) string {
+	var changes []string
+	for _, v := range add {
+		changes = append(changes, "+"+v)
+	}
+	for _, v := range rm {
+		changes = append(changes, "-"+v)
+	}
+	sort.Strings(changes)
+	return fmt.Sprintf("%s %s", label, strings.Join(changes, " "))
+}
+
+func describeExport(e *jwt.Export) string {
+	visibility := "public"
+	if e.TokenReq {
+		visibility = "private"
+	}
+	s := fmt.Sprintf("%s %s", visibility, e.Type)
+	if e.Name != string(e.Subject) {
+		s = fmt.Sprintf("%s %s", s, strconv.Quote(e.Name))
+	}
+	return s
+}
+
+// childName names an entity by its parent
+func childName(parent string, name string) string {
+	return fmt.Sprintf("%s/%s", parent, name)
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+const applySpec = `
+operators:
+- name: apply
+  accounts:
+  - name: A
+    tags: [Prod]
+    limits:
+      conns: 10
+    exports:
+    - subject: a.>
+      private: true
+    - subject: help
+      type: service
+  - name: B
+    imports:
+    - account: A
+      subject: a.>
+      to: from_a
+    users:
+    - name: u
+      allow_pub: [b.>]
+      allow_sub: [b.>, _INBOX.>]
+      deny_sub: [b.private]
+      source_networks: [192.168.1.0/24]
+  clusters:
+  - name: C
+    account_url_template: http://localhost:9090/jwt/v1/accounts/
+    trusted_accounts: [A, B]
+    servers:
+    - name: s1
+      tags: [east]
+`
+
+func writeSpec(t *testing.T, ts *TestStore, spec string) string {
+	fp := filepath.Join(ts.Dir, "spec.yaml")
+	require.NoError(t, ioutil.WriteFile(fp, []byte(spec), 0600))
+	return fp
+}
+
+func Test_ApplyCreates(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+
+	fp := writeSpec(t, ts, applySpec)
+	_, stderr, err := ExecuteCmd(createApplyCmd(), "--file", fp)
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "apply create account A tags +prod; conns 10")
+	require.Contains(t, stderr, "apply create export A/a.> private stream")
+	require.Contains(t, stderr, "apply create import B/a.> from A; to from_a")
+	require.Contains(t, stderr, "apply create server C/s1 tags +east")
+	require.Contains(t, stderr, "Success! - applied")
+
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Equal(t, jwt.TagList{"prod"}, a.Tags)
+	require.Equal(t, int64(10), a.Limits.Conn)
+	require.Len(t, a.Exports, 2)
+
+	b, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Len(t, b.Imports, 1)
+	require.Equal(t, a.Subject, b.Imports[0].Account)
+	require.Equal(t, jwt.Subject("from_a"), b.Imports[0].To)
+
+	u, err := ts.Store.ReadUserClaim("B", "u")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"b.>"}, u.Pub.Allow)
+	require.ElementsMatch(t, []string{"b.>", "_INBOX.>"}, u.Sub.Allow)
+	require.ElementsMatch(t, []string{"b.private"}, u.Sub.Deny)
+	require.Equal(t, "192.168.1.0/24", u.Src)
+
+	c, err := ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{a.Subject, b.Subject}, c.Accounts)
+	require.Equal(t, "http://localhost:9090/jwt/v1/accounts/", c.AccountURL)
+	require.True(t, ts.Store.Has(store.Clusters, "C", store.Servers, store.JwtName("s1")))
+
+	// applying again is a no-op
+	_, stderr, err = ExecuteCmd(createApplyCmd(), "--file", fp)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No changes - the store matches the spec")
+}
+
+func Test_ApplyUpdates(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, applySpec))
+	require.NoError(t, err)
+
+	spec := `
+operators:
+- name: apply
+  accounts:
+  - name: A
+    tags: [dev]
+    exports:
+    - subject: a.>
+  - name: B
+    users:
+    - name: u
+      allow_pub: [b.>]
+      allow_sub: [b.>]
+      deny_pub: [_INBOX.>]
+`
+	_, stderr, err := ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, spec))
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "apply update account A tags +dev -prod")
+	require.Contains(t, stderr, "apply delete export A/help")
+	require.Contains(t, stderr, "apply update export A/a.> public stream")
+	require.Contains(t, stderr, "apply delete import B/a.>")
+
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Equal(t, jwt.TagList{"dev"}, a.Tags)
+	require.Len(t, a.Exports, 1)
+	require.False(t, a.Exports[0].TokenReq)
+
+	b, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Empty(t, b.Imports)
+
+	// _INBOX.> moves from the subscribe allow to the publish deny list
+	u, err := ts.Store.ReadUserClaim("B", "u")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"b.>"}, u.Pub.Allow)
+	require.ElementsMatch(t, []string{"b.>"}, u.Sub.Allow)
+	require.ElementsMatch(t, []string{"_INBOX.>"}, u.Pub.Deny)
+	require.Empty(t, u.Sub.Deny)
+	require.Empty(t, u.Src)
+
+	_, stderr, err = ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, spec))
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No changes - the store matches the spec")
+}
+
+func Test_ApplyDryRun(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+
+	_, stderr, err := ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, applySpec), "--dry-run")
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "apply create account A")
+	require.Contains(t, stderr, "Dry run")
+	require.False(t, ts.Store.Has(store.Accounts, "A", store.JwtName("A")))
+}
+
+func Test_ApplyCreatesOperator(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+
+	spec := `
+operators:
+- name: other
+  accounts:
+  - name: X
+    users:
+    - name: x
+`
+	_, stderr, err := ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, spec))
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "other create operator other")
+
+	s, err := store.LoadStore(filepath.Join(ts.GetStoresRoot(), "other"))
+	require.NoError(t, err)
+	require.True(t, s.Has(store.Accounts, "X", store.Users, store.JwtName("x")))
+	ctx, err := s.GetContext()
+	require.NoError(t, err)
+	kp, err := ctx.KeyStore.GetOperatorKey("other")
+	require.NoError(t, err)
+	require.NotNil(t, kp)
+
+	// the current operator is unchanged
+	require.Equal(t, "apply", GetConfig().Operator)
+}
+
+func Test_ApplyInvalidSpec(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+
+	spec := `
+operators:
+- name: apply
+  accounts:
+  - name: A
+  - name: A
+`
+	_, _, err := ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, spec))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `account "A" is specified more than once`)
+
+	_, _, err = ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, "operators:\n- name: apply\n  acounts: []\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error parsing spec")
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// AuditLog is the name of the audit log in the store directory
+const AuditLog = "audit.log"
+
+const redacted = "[REDACTED]"
+
+// SecretFlag is the annotation of the flags whose value is not logged
+const SecretFlag = "nsc_secret"
+
+// kinds of the changes to the keystore
+const (
+	SeedRestored = "seed restored"
+	SeedRemoved  = "seed removed"
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query the audit log of the changes to the store",
+}
+
+func init() {
+	GetRootCmd().AddCommand(auditCmd)
+}
+
+// AuditRecord describes a claim, activation or seed changed by a command
+type AuditRecord struct {
+	Time      time.Time         `json:"time"`
+	User      string            `json:"user"`
+	Command   string            `json:"command"`
+	Flags     map[string]string `json:"flags,omitempty"`
+	Kind      string            `json:"kind"`
+	Name      string            `json:"name"`
+	PublicKey string            `json:"public_key"`
+	Signer    string            `json:"signer"`
+	PrevID    string            `json:"prev_jwt_id,omitempty"`
+	ID        string            `json:"jwt_id"`
+}
+
+// AuditAction appends a record to the audit log of the store for each change
+// the action made. Actions that don't change the store are not logged.
+func AuditAction(ctx ActionCtx) error {
+	if ctx == nil || ctx.StoreCtx() == nil || ctx.StoreCtx().Store == nil {
+		return nil
+	}
+	command := ""
+	var flags map[string]string
+	if cmd := ctx.CurrentCmd(); cmd != nil {
+		command = cmd.CommandPath()
+		flags = auditFlags(cmd)
+	}
+	return auditChanges(ctx.StoreCtx().Store, command, flags)
+}
+
+// auditChanges appends a record to the audit log of the store for each
+// change recorded by the store since the last audit
+func auditChanges(s *store.Store, command string, flags map[string]string) error {
+	changes := s.TakeChanges()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	username := osUser()
+
+	fp := filepath.Join(s.Dir, AuditLog)
+	f, err := os.OpenFile(fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening audit log %q: %v", fp, err)
+	}
+	defer f.Close()
+	for _, c := range changes {
+		r := AuditRecord{Time: now, User: username, Command: command, Flags: flags,
+			Kind: c.Kind, Name: c.Name, PublicKey: c.PublicKey, Signer: c.Issuer, PrevID: c.PrevID, ID: c.ID}
+		d, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("error serializing audit record: %v", err)
+		}
+		if _, err := f.Write(append(d, '\n')); err != nil {
+			return fmt.Errorf("error writing audit log %q: %v", fp, err)
+		}
+	}
+	return f.Sync()
+}
+
+// ReadAuditLog reads the records in the audit log of the store directory
+func ReadAuditLog(dir string) ([]AuditRecord, error) {
+	fp := filepath.Join(dir, AuditLog)
+	f, err := os.Open(fp)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log %q: %v", fp, err)
+	}
+	defer f.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var r AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("error parsing audit log %q line %d: %v", fp, line, err)
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit log %q: %v", fp, err)
+	}
+	return records, nil
+}
+
+func osUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// markSecret sets the SecretFlag annotation on the flag
+func markSecret(cmd *cobra.Command, name string) {
+	if err := cmd.Flags().SetAnnotation(name, SecretFlag, []string{"true"}); err != nil {
+		panic(err)
+	}
+}
+
+// auditFlags returns the flags set on the command with seeds and secret flags redacted
+func auditFlags(cmd *cobra.Command) map[string]string {
+	flags := make(map[string]string)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[SecretFlag]; ok {
+			flags[f.Name] = redacted
+			return
+		}
+		flags[f.Name] = redactSeeds(f.Value.String())
+	})
+	if len(flags) == 0 {
+		return nil
+	}
+	return flags
+}
+
+// redactSeeds replaces the nkey seeds in v
+func redactSeeds(v string) string {
+	fields := strings.FieldsFunc(v, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, f := range fields {
+		if _, err := nkeys.FromSeed([]byte(f)); err == nil {
+			v = strings.Replace(v, f, redacted, -1)
+		}
+	}
+	return v
+}
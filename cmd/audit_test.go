@@ -0,0 +1,191 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AuditRecordsChanges(t *testing.T) {
+	ts := NewTestStore(t, "audit")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	_, _, err := ExecuteCmd(createEditAccount(), "--account", "A", "--tag", "prod")
+	require.NoError(t, err)
+	// reads are not logged
+	_, _, err = ExecuteCmd(createDescribeAccountCmd(), "--account", "A")
+	require.NoError(t, err)
+
+	records, err := ReadAuditLog(ts.Store.Dir)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	ac, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	opk, err := ts.Store.GetRootPublicKey()
+	require.NoError(t, err)
+	for _, r := range records {
+		require.Equal(t, string(jwt.AccountClaim), r.Kind)
+		require.Equal(t, "A", r.Name)
+		require.Equal(t, ac.Subject, r.PublicKey)
+		require.Equal(t, opk, r.Signer)
+		require.NotEmpty(t, r.User)
+		require.WithinDuration(t, time.Now(), r.Time, time.Minute)
+	}
+	require.Empty(t, records[0].PrevID)
+	require.Equal(t, records[0].ID, records[1].PrevID)
+	require.Equal(t, ac.ID, records[1].ID)
+	require.Equal(t, "[prod]", records[1].Flags["tag"])
+	require.Equal(t, "A", records[1].Flags["account"])
+}
+
+func Test_AuditRedactsSeeds(t *testing.T) {
+	kp, err := nkeys.CreateAccount()
+	require.NoError(t, err)
+	seed, err := kp.Seed()
+	require.NoError(t, err)
+	pk, err := kp.PublicKey()
+	require.NoError(t, err)
+
+	v := redactSeeds(string(seed))
+	require.Equal(t, redacted, v)
+	v = redactSeeds("[" + pk + "," + string(seed) + "]")
+	require.Equal(t, "["+pk+","+redacted+"]", v)
+	require.Equal(t, "/path/key.nk", redactSeeds("/path/key.nk"))
+}
+
+func Test_AuditLogQuery(t *testing.T) {
+	ts := NewTestStore(t, "audit")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	ts.AddUser(t, "A", "u")
+	ts.AddAccount(t, "B")
+
+	decode := func(out string) []AuditRecord {
+		var records []AuditRecord
+		for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+			if l == "" {
+				continue
+			}
+			var r AuditRecord
+			require.NoError(t, json.Unmarshal([]byte(l), &r))
+			records = append(records, r)
+		}
+		return records
+	}
+
+	stdout, _, err := ExecuteCmd(createAuditLogCmd(), "--json")
+	require.NoError(t, err)
+	require.Len(t, decode(stdout), 3)
+
+	stdout, _, err = ExecuteCmd(createAuditLogCmd(), "--json", "--entity", "A")
+	require.NoError(t, err)
+	records := decode(stdout)
+	require.Len(t, records, 1)
+	require.Equal(t, "A", records[0].Name)
+
+	upk := records[0].PublicKey
+	stdout, _, err = ExecuteCmd(createAuditLogCmd(), "--json", "--entity", upk)
+	require.NoError(t, err)
+	require.Len(t, decode(stdout), 1)
+
+	stdout, _, err = ExecuteCmd(createAuditLogCmd(), "--json", "--kind", "user")
+	require.NoError(t, err)
+	records = decode(stdout)
+	require.Len(t, records, 1)
+	require.Equal(t, "u", records[0].Name)
+
+	today := time.Now().Format(auditDateFormat)
+	stdout, _, err = ExecuteCmd(createAuditLogCmd(), "--json", "--since", today, "--until", today)
+	require.NoError(t, err)
+	require.Len(t, decode(stdout), 3)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(auditDateFormat)
+	_, stderr, err := ExecuteCmd(createAuditLogCmd(), "--since", tomorrow)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No changes in the audit log")
+
+	stdout, _, err = ExecuteCmd(createAuditLogCmd())
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stdout), "account A")
+
+	_, _, err = ExecuteCmd(createAuditLogCmd(), "--since", "yesterday")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid --since")
+}
+
+func Test_AuditRedactsSecretFlags(t *testing.T) {
+	cmd := createKeysBackupCmd()
+	require.NoError(t, cmd.Flags().Set("passphrase-file", "/path/pass.txt"))
+	require.NoError(t, cmd.Flags().Set("output-file", "/path/out.backup"))
+	flags := auditFlags(cmd)
+	require.Equal(t, redacted, flags["passphrase-file"])
+	require.Equal(t, "/path/out.backup", flags["output-file"])
+}
+
+func Test_AuditRecordsActivations(t *testing.T) {
+	ts := NewTestStore(t, "audit")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo.>", false)
+	ts.AddAccount(t, "B")
+	token := ts.GenerateActivation(t, "A", "foo.>", "B")
+	ac, err := jwt.DecodeActivationClaims(token)
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createRevokeActivationCmd(), "--account", "A", "--jti", ac.ID)
+	require.NoError(t, err)
+
+	records, err := ReadAuditLog(ts.Store.Dir)
+	require.NoError(t, err)
+	var found bool
+	for _, r := range records {
+		if r.Kind == store.ActivationChange && r.Command == "activation" {
+			require.Equal(t, "A", r.Name)
+			require.Equal(t, ac.Subject, r.PublicKey)
+			require.Equal(t, ac.ID, r.ID)
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func Test_AuditRecordsSeeds(t *testing.T) {
+	ts := NewTestStore(t, "audit")
+	defer ts.Done(t)
+
+	_, pk, akp := CreateAccountKey(t)
+	_, err := ts.KeyStore.Store("X", akp, "")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createKeysPruneCmd(), "--force")
+	require.NoError(t, err)
+
+	records, err := ReadAuditLog(ts.Store.Dir)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, SeedRemoved, records[0].Kind)
+	require.Equal(t, "X", records[0].Name)
+	require.Equal(t, pk, records[0].PublicKey)
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+const auditDateFormat = "2006-01-02"
+
+func createAuditLogCmd() *cobra.Command {
+	var params AuditLogParams
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "List the changes recorded in the audit log",
+		Long: `List the changes recorded in the audit log
+
+Every command that writes a JWT or an activation to the store, or restores or
+removes seeds in the keystore, records the time, OS user, command, flags,
+entity and the previous and new JWT IDs. Seeds and secret flags such as
+--passphrase-file are redacted.
+
+Dates are in the 2006-01-02 or RFC3339 formats, the until date is inclusive.`,
+		Example: `nsc audit log
+nsc audit log --entity A
+nsc audit log --kind user --since 2018-12-01 --until 2018-12-31
+nsc audit log --json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunAction(cmd, args, &params)
+		},
+	}
+	cmd.Flags().StringVarP(&params.entity, "entity", "e", "", "entity name or public key")
+	cmd.Flags().StringVarP(&params.kind, "kind", "", "", "entity kind (operator, account, user, cluster, server, activation, seed restored or seed removed)")
+	cmd.Flags().StringVarP(&params.sinceArg, "since", "", "", "list changes on or after the date")
+	cmd.Flags().StringVarP(&params.untilArg, "until", "", "", "list changes on or before the date")
+	cmd.Flags().BoolVarP(&params.json, "json", "", false, "output the records as json lines")
+	cmd.Flags().StringVarP(&params.outputFile, "output-file", "o", "--", "output file, '--' is stdout")
+
+	return cmd
+}
+
+func init() {
+	auditCmd.AddCommand(createAuditLogCmd())
+}
+
+type AuditLogParams struct {
+	entity     string
+	kind       string
+	sinceArg   string
+	untilArg   string
+	since      time.Time
+	until      time.Time
+	json       bool
+	outputFile string
+	records    []AuditRecord
+}
+
+func (p *AuditLogParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *AuditLogParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *AuditLogParams) Load(ctx ActionCtx) error {
+	records, err := ReadAuditLog(ctx.StoreCtx().Store.Dir)
+	if err != nil {
+		return err
+	}
+	p.records = records
+	return nil
+}
+
+func (p *AuditLogParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *AuditLogParams) Validate(ctx ActionCtx) error {
+	var err error
+	if p.sinceArg != "" {
+		if p.since, err = parseAuditDate(p.sinceArg, false); err != nil {
+			return fmt.Errorf("invalid --since: %v", err)
+		}
+	}
+	if p.untilArg != "" {
+		if p.until, err = parseAuditDate(p.untilArg, true); err != nil {
+			return fmt.Errorf("invalid --until: %v", err)
+		}
+	}
+	if !p.since.IsZero() && !p.until.IsZero() && p.until.Before(p.since) {
+		return fmt.Errorf("--until is before --since")
+	}
+	return nil
+}
+
+// parseAuditDate parses a date or a time, for the end of a range a date
+// includes the whole day
+func parseAuditDate(v string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(auditDateFormat, v, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date in the %s or RFC3339 formats", v, auditDateFormat)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// Match returns true if the record is in the query
+func (p *AuditLogParams) Match(r AuditRecord) bool {
+	if p.entity != "" && r.Name != p.entity && r.PublicKey != p.entity {
+		return false
+	}
+	if p.kind != "" && !strings.EqualFold(r.Kind, p.kind) {
+		return false
+	}
+	if !p.since.IsZero() && r.Time.Before(p.since) {
+		return false
+	}
+	if !p.until.IsZero() && r.Time.After(p.until) {
+		return false
+	}
+	return true
+}
+
+func (p *AuditLogParams) Run(ctx ActionCtx) error {
+	var records []AuditRecord
+	for _, r := range p.records {
+		if p.Match(r) {
+			records = append(records, r)
+		}
+	}
+
+	if p.json {
+		var buf bytes.Buffer
+		for _, r := range records {
+			d, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			buf.Write(d)
+			buf.WriteByte('\n')
+		}
+		return Write(p.outputFile, buf.Bytes())
+	}
+
+	if len(records) == 0 {
+		ctx.CurrentCmd().Println("No changes in the audit log")
+		return nil
+	}
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle("Audit Log")
+	table.AddHeaders("Time", "User", "Command", "Kind", "Name", "Public Key", "JWT ID")
+	for _, r := range records {
+		table.AddRow(r.Time.Local().Format(time.RFC3339), r.User, r.Command, r.Kind, r.Name, r.PublicKey, r.ID)
+	}
+	return Write(p.outputFile, []byte(table.Render()))
+}
@@ -23,10 +23,11 @@ import (
 
 type ClusterContextParams struct {
 	Name string
+	err  error
 }
 
 func (p *ClusterContextParams) BindFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(&p.Name, "cluster", "c", "", "cluster name")
+	cmd.Flags().StringVarP(&p.Name, "cluster", "c", "", "cluster name or public key prefix")
 }
 
 func (p *ClusterContextParams) SetDefaults(ctx ActionCtx) {
@@ -40,6 +41,23 @@ func (p *ClusterContextParams) SetDefaults(ctx ActionCtx) {
 	if ctx.StoreCtx().Cluster.Name != "" && p.Name == "" {
 		p.Name = ctx.StoreCtx().Cluster.Name
 	}
+	p.resolve(ctx)
+}
+
+// resolve replaces a public key prefix with the name of the cluster
+func (p *ClusterContextParams) resolve(ctx ActionCtx) {
+	if p.Name == "" {
+		return
+	}
+	n, err := ctx.StoreCtx().Store.ResolveCluster(p.Name)
+	if err != nil {
+		p.err = err
+		return
+	}
+	if ctx.StoreCtx().Cluster.Name == p.Name {
+		ctx.StoreCtx().Cluster.Name = n
+	}
+	p.Name = n
 }
 
 func (p *ClusterContextParams) Edit(ctx ActionCtx) error {
@@ -48,6 +66,7 @@ func (p *ClusterContextParams) Edit(ctx ActionCtx) error {
 	if err != nil {
 		return err
 	}
+	p.resolve(ctx)
 	ctx.StoreCtx().Cluster.Name = p.Name
 	return nil
 }
@@ -58,5 +77,5 @@ func (p *ClusterContextParams) Validate(ctx ActionCtx) error {
 		ctx.CurrentCmd().SilenceUsage = false
 		return errors.New("a cluster is required")
 	}
-	return nil
+	return p.err
 }
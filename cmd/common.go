@@ -112,6 +112,20 @@ func Write(fp string, data []byte) error {
 	return nil
 }
 
+// WriteSecret writes data that should only be readable by the owner, the file must not exist
+func WriteSecret(fp string, data []byte) error {
+	if IsStdOut(fp) {
+		return Write(fp, data)
+	}
+	if _, err := os.Stat(fp); err == nil {
+		return fmt.Errorf("%q already exists", fp)
+	}
+	if err := ioutil.WriteFile(fp, data, 0600); err != nil {
+		return fmt.Errorf("error writing %q: %v", fp, err)
+	}
+	return nil
+}
+
 func ReadJson(fp string, v interface{}) error {
 	data, err := Read(fp)
 	if err != nil {
@@ -164,27 +178,18 @@ func FormatJwt(jwtType string, jwtString string) []byte {
 	return w.Bytes()
 }
 
+// armoredToken matches a token between the BEGIN and END lines written by FormatJwt and
+// FormatKeys, the dashes of the token are kept as they are valid in base64url
+var armoredToken = regexp.MustCompile(`(?s)-{3,}\s*BEGIN[^\n]*?(JWT|KEY|SEED)\s*-{3,}\s+(.+?)\s+-{3,}\s*END[^\n]*?(JWT|KEY|SEED)\s*-{3,}`)
+
+// ExtractToken returns the token in an armored JWT or key, or s if it isn't armored
 func ExtractToken(s string) string {
-	// remove all the spaces
-	re := regexp.MustCompile(`\s+`)
-	w := re.ReplaceAllString(s, "")
-	// remove multiple dashes
-	re = regexp.MustCompile(`\-+`)
-	w = re.ReplaceAllString(w, "-")
-
-	// the token can now look like
-	// -BEGINXXXXPUBKEY-token-ENDXXXXPUBKEY-
-	re = regexp.MustCompile(`(?m)(\-BEGIN.+(JWT|KEY|SEED)\-)(?P<token>.+)(\-END.+(JWT|KEY|SEED)\-)`)
-	// find the index of the token
-	m := re.FindStringSubmatch(w)
-	if len(m) > 0 {
-		for i, name := range re.SubexpNames() {
-			if name == "token" {
-				return m[i]
-			}
-		}
+	m := armoredToken.FindStringSubmatch(s)
+	if m == nil {
+		return s
 	}
-	return s
+	// the token may be wrapped over several lines
+	return strings.Join(strings.Fields(m[2]), "")
 }
 
 func ParseNumber(s string) (int64, error) {
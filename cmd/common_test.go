@@ -328,3 +328,14 @@ func TestCommon_ReadJSON(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "test", n.Name)
 }
+
+func TestCommon_ExtractToken(t *testing.T) {
+	// base64url tokens can have dashes, also next to the armor
+	token := "eyJ0eXAi.eyJqdGki--x.sig--a-"
+	require.Equal(t, token, ExtractToken(string(FormatJwt("Account", token))))
+	require.Equal(t, token, ExtractToken(string(FormatConfig("User", token, "SUSEED"))))
+	require.Equal(t, "SUSEED", ExtractToken("-----BEGIN USER NKEY SEED-----\nSUSEED\n------END USER NKEY SEED------\n"))
+	wrapped := "-----BEGIN NATS ACCOUNT JWT-----\n  eyJ0eXAi.eyJq\n  dGki--x.sig--a-\n------END NATS ACCOUNT JWT------"
+	require.Equal(t, token, ExtractToken(wrapped))
+	require.Equal(t, token, ExtractToken(token))
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// configHome points the tool config to a temp directory and returns a func restoring it
+func configHome(t *testing.T) func() {
+	old := toolHome
+	toolHome = MakeTempDir(t)
+	return func() {
+		toolHome = old
+	}
+}
+
+func Test_ContextAddUseList(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer configHome(t)()
+
+	ts.AddAccount(t, "A")
+	ForceAccount(t, "A")
+	_, stderr, err := ExecuteCmd(createContextListCmd())
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No contexts defined")
+
+	_, _, err = ExecuteCmd(createContextAddCmd(), "dev")
+	require.NoError(t, err)
+
+	ts.AddOperator(t, "prod")
+	_, _, err = ExecuteCmd(createContextAddCmd(), "prod", "--operator", "prod")
+	require.NoError(t, err)
+
+	conf := GetConfig()
+	require.Len(t, conf.Contexts, 2)
+	require.Equal(t, "dev", conf.Contexts["dev"].Operator)
+	require.Equal(t, "A", conf.Contexts["dev"].Account)
+	require.Equal(t, "prod", conf.Contexts["prod"].Operator)
+	require.Equal(t, "dev", conf.Operator)
+
+	_, stderr, err = ExecuteCmd(createContextUseCmd(), "prod")
+	require.NoError(t, err)
+	require.Contains(t, stderr, `switched to context "prod"`)
+	require.Equal(t, "prod", conf.Operator)
+	require.Equal(t, "prod", conf.CurrentContext)
+
+	s, err := GetStore()
+	require.NoError(t, err)
+	require.Equal(t, "prod", s.GetName())
+
+	_, stderr, err = ExecuteCmd(createContextListCmd())
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "dev "+ts.GetStoresRoot()+" dev A")
+	require.Contains(t, stderr, "* prod "+ts.GetStoresRoot()+" prod")
+
+	var saved ToolConfig
+	require.NoError(t, ReadJson(filepath.Join(toolHome, filepath.Base(conf.configFile())), &saved))
+	require.Equal(t, "prod", saved.CurrentContext)
+	require.Len(t, saved.Contexts, 2)
+}
+
+func Test_ContextUnknown(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer configHome(t)()
+
+	_, _, err := ExecuteCmd(createContextUseCmd(), "foo")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `context "foo" doesn't exist`)
+	_, _, err = ExecuteCmd(createContextDeleteCmd(), "foo")
+	require.Error(t, err)
+	_, _, err = ExecuteCmd(createContextAddCmd(), "foo", "--operator", "bar")
+	require.Error(t, err)
+	_, _, err = ExecuteCmd(createContextUseCmd())
+	require.Error(t, err)
+}
+
+func Test_ContextDelete(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer configHome(t)()
+
+	_, _, err := ExecuteCmd(createContextAddCmd(), "dev")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createContextUseCmd(), "dev")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createContextDeleteCmd(), "dev")
+	require.NoError(t, err)
+
+	conf := GetConfig()
+	require.Empty(t, conf.Contexts)
+	require.Empty(t, conf.CurrentContext)
+	require.Equal(t, "dev", conf.Operator)
+}
+
+func Test_ContextFlagIsNotSaved(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer configHome(t)()
+	defer func() {
+		ContextFlag = ""
+	}()
+
+	ts.AddOperator(t, "prod")
+	conf := GetConfig()
+	require.NoError(t, conf.AddContext("prod", ContextConfig{StoreRoot: ts.GetStoresRoot(), Operator: "prod"}))
+
+	ContextFlag = "prod"
+	require.NoError(t, ApplyContextFlag())
+	s, err := GetStore()
+	require.NoError(t, err)
+	require.Equal(t, "prod", s.GetName())
+
+	require.NoError(t, conf.Save())
+	var saved ToolConfig
+	require.NoError(t, ReadJson(conf.configFile(), &saved))
+	require.Equal(t, "dev", saved.Operator)
+	require.Empty(t, saved.CurrentContext)
+	require.Len(t, saved.Contexts, 1)
+
+	ContextFlag = "foo"
+	require.Error(t, ApplyContextFlag())
+}
+
+func Test_ContextUseAndDeleteWithOverride(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer configHome(t)()
+	defer func() {
+		ContextFlag = ""
+	}()
+
+	ts.AddOperator(t, "prod")
+	conf := GetConfig()
+	require.NoError(t, conf.AddContext("dev", ContextConfig{StoreRoot: ts.GetStoresRoot(), Operator: "dev"}))
+	require.NoError(t, conf.AddContext("prod", ContextConfig{StoreRoot: ts.GetStoresRoot(), Operator: "prod"}))
+
+	ContextFlag = "dev"
+	require.NoError(t, ApplyContextFlag())
+
+	_, _, err := ExecuteCmd(createContextUseCmd(), "prod")
+	require.NoError(t, err)
+	var saved ToolConfig
+	require.NoError(t, ReadJson(conf.configFile(), &saved))
+	require.Equal(t, "prod", saved.CurrentContext)
+	require.Equal(t, "prod", saved.Operator)
+
+	_, _, err = ExecuteCmd(createContextDeleteCmd(), "prod")
+	require.NoError(t, err)
+	saved = ToolConfig{}
+	require.NoError(t, ReadJson(conf.configFile(), &saved))
+	require.Empty(t, saved.CurrentContext)
+	require.Len(t, saved.Contexts, 1)
+}
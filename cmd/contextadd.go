@@ -0,0 +1,66 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func createContextAddCmd() *cobra.Command {
+	var params SetContextParams
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Save a named context",
+		Long: `Save a named context
+
+Without flags the current environment is saved under the name. Flags set the
+store directory, operator, account and cluster of the context the same way
+'env' does. An existing context with the same name is replaced.`,
+		Example: `context add dev
+context add prod --store /opt/nsc/prod --operator O --account A`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := GetConfig()
+			c := config.ContextConfig
+			if params != (SetContextParams{}) {
+				v, err := params.ContextConfig(&c)
+				if err != nil {
+					return err
+				}
+				c = *v
+			}
+			if err := config.AddContext(args[0], c); err != nil {
+				return err
+			}
+			if err := config.Save(); err != nil {
+				return err
+			}
+			cmd.Printf("Success! - added context %q\n", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.StoreRoot, "store", "s", "", "store directory")
+	cmd.Flags().StringVarP(&params.Operator, "operator", "o", "", "operator name")
+	cmd.Flags().StringVarP(&params.Account, "account", "a", "", "account name")
+	cmd.Flags().StringVarP(&params.Cluster, "cluster", "c", "", "cluster name")
+
+	return cmd
+}
+
+func init() {
+	contextCmd.AddCommand(createContextAddCmd())
+}
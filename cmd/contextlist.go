@@ -0,0 +1,58 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+func createContextListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the named contexts",
+		Example:      "context list",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := GetConfig()
+			names := config.ListContexts()
+			if len(names) == 0 {
+				cmd.Println("No contexts defined - add one with `context add`")
+				return nil
+			}
+			table := tablewriter.CreateTable()
+			table.UTF8Box()
+			table.AddTitle("Contexts")
+			table.AddHeaders("Current", "Name", "Stores Dir", "Operator", "Account", "Cluster")
+			for _, n := range names {
+				c := config.Contexts[n]
+				current := ""
+				if n == config.CurrentContext {
+					current = "*"
+				}
+				table.AddRow(current, n, c.StoreRoot, c.Operator, c.Account, c.Cluster)
+			}
+			cmd.Println(table.Render())
+			return nil
+		},
+	}
+	return cmd
+}
+
+func init() {
+	contextCmd.AddCommand(createContextListCmd())
+}
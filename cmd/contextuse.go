@@ -0,0 +1,46 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func createContextUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "use <name>",
+		Short:        "Make a named context the current context",
+		Example:      "context use prod",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := GetConfig()
+			if err := config.UseContext(args[0]); err != nil {
+				return err
+			}
+			if err := config.Save(); err != nil {
+				return err
+			}
+			cmd.Printf("Success! - switched to context %q\n", args[0])
+			return nil
+		},
+	}
+	return cmd
+}
+
+func init() {
+	contextCmd.AddCommand(createContextUseCmd())
+}
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/mitchellh/go-homedir"
@@ -32,12 +34,22 @@ type ToolConfig struct {
 	ContextConfig
 	GithubUpdates string `json:"github_updates"` // git hub repo
 	LastUpdate    int64  `json:"last_update"`
+	// disables the daily check for updates
+	DisableUpdateChecks bool `json:"disable_update_checks,omitempty"`
+	// public key that signs release checksums, for builds without a ReleaseSigningKey
+	UpdateSigningKey string `json:"update_signing_key,omitempty"`
+	// named contexts
+	Contexts       map[string]ContextConfig `json:"contexts,omitempty"`
+	CurrentContext string                   `json:"current_context,omitempty"`
 }
 
 var config ToolConfig
 var toolHome string
 var homeEnv string
 
+// the saved context while a context is selected by --context
+var savedContext *ToolConfig
+
 // GetConfig returns the global config
 func GetConfig() *ToolConfig {
 	return &config
@@ -45,6 +57,9 @@ func GetConfig() *ToolConfig {
 
 func ResetConfigForTests() {
 	config = ToolConfig{}
+	savedContext = nil
+	storeSource = defaultStoreSource
+	settingSources = make(map[string]string)
 }
 
 func LoadOrInit(github string, toolHomeEnvName string) (*ToolConfig, error) {
@@ -64,7 +79,7 @@ func LoadOrInit(github string, toolHomeEnvName string) (*ToolConfig, error) {
 	}
 
 	// is the struct modified from the file
-	if (ToolConfig{}) == config {
+	if reflect.DeepEqual(ToolConfig{}, config) {
 		config.GithubUpdates = github
 
 		// ~/.ngs_cli/nats
@@ -83,6 +98,14 @@ func LoadOrInit(github string, toolHomeEnvName string) (*ToolConfig, error) {
 	// trigger updating defaults
 	config.SetDefaults()
 
+	if config.CurrentContext != "" {
+		storeSource = fmt.Sprintf("context %q", config.CurrentContext)
+	}
+	// a store containing the working directory wins over the configuration
+	if err := config.DiscoverLocalStore(); err != nil {
+		return nil, fmt.Errorf("error loading local store: %v", err)
+	}
+
 	return &config, nil
 }
 
@@ -103,9 +126,91 @@ func (d *ToolConfig) load() error {
 }
 
 func (d *ToolConfig) Save() error {
+	if savedContext != nil {
+		// don't persist the context selected by --context
+		c := *d
+		c.ContextConfig = savedContext.ContextConfig
+		c.CurrentContext = savedContext.CurrentContext
+		return WriteJson(d.configFile(), &c)
+	}
 	return WriteJson(d.configFile(), d)
 }
 
+// AddContext saves the context under the specified name, replacing any existing context with that name
+func (d *ToolConfig) AddContext(name string, c ContextConfig) error {
+	if name == "" {
+		return errors.New("context name is required")
+	}
+	if d.Contexts == nil {
+		d.Contexts = make(map[string]ContextConfig)
+	}
+	d.Contexts[name] = c
+	return nil
+}
+
+// UseContext makes the named context the current context
+func (d *ToolConfig) UseContext(name string) error {
+	c, ok := d.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q doesn't exist", name)
+	}
+	d.ContextConfig = c
+	d.CurrentContext = name
+	if savedContext != nil {
+		// Save persists the saved context while the context is overridden
+		savedContext.ContextConfig = c
+		savedContext.CurrentContext = name
+	}
+	return nil
+}
+
+// DeleteContext removes the named context, the current context settings are kept
+func (d *ToolConfig) DeleteContext(name string) error {
+	if _, ok := d.Contexts[name]; !ok {
+		return fmt.Errorf("context %q doesn't exist", name)
+	}
+	delete(d.Contexts, name)
+	if d.CurrentContext == name {
+		d.CurrentContext = ""
+	}
+	if savedContext != nil && savedContext.CurrentContext == name {
+		savedContext.CurrentContext = ""
+	}
+	return nil
+}
+
+// OverrideContext uses the named context without saving it as the current context
+func (d *ToolConfig) OverrideContext(name string) error {
+	c, ok := d.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q doesn't exist", name)
+	}
+	d.override(c)
+	d.CurrentContext = name
+	storeSource = fmt.Sprintf("context %q - selected by --context", name)
+	clearContextSources()
+	return nil
+}
+
+// override replaces the context settings without saving them
+func (d *ToolConfig) override(c ContextConfig) {
+	if savedContext == nil {
+		saved := *d
+		savedContext = &saved
+	}
+	d.ContextConfig = c
+}
+
+// ListContexts returns the names of the contexts sorted
+func (d *ToolConfig) ListContexts() []string {
+	var names []string
+	for k := range d.Contexts {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetToolHome returns the . folder used fro this CLIs config and optionally the projects
 func initToolHome(envVarName string) (string, error) {
 	toolHome = os.Getenv(envVarName)
@@ -21,24 +21,35 @@ import (
 	"github.com/nats-io/jwt"
 	"github.com/nats-io/nkeys"
 	"github.com/nats-io/nsc/cli"
+	"github.com/nats-io/nsc/cmd/store"
 	"github.com/spf13/cobra"
 )
 
 func createDeleteExportCmd() *cobra.Command {
 	var params DeleteExportParams
 	cmd := &cobra.Command{
-		Use:          "export",
-		Short:        "Delete an export",
+		Use:   "export",
+		Short: "Delete an export",
+		Example: `nsc delete export -i
+nsc delete export -s "bar.>" --cascade`,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := RunAction(cmd, args, &params); err != nil {
 				return err
 			}
 			cmd.Printf("Success! - deleted export of %q\n", params.deletedExport.Subject)
+			for _, v := range params.dangling {
+				if params.cascade {
+					cmd.Printf("Deleted import of %q from account %q\n", v.Import.Subject, v.Account)
+				} else {
+					cmd.Printf("Warning! - account %q still imports %q - delete it with `--cascade` or `delete import`\n", v.Account, v.Import.Subject)
+				}
+			}
 			return nil
 		},
 	}
 	cmd.Flags().StringVarP(&params.subject, "subject", "s", "", "subject")
+	cmd.Flags().BoolVarP(&params.cascade, "cascade", "", false, "delete imports of the export from other accounts in the store")
 	params.AccountContextParams.BindFlags(cmd)
 
 	return cmd
@@ -51,12 +62,21 @@ func init() {
 type DeleteExportParams struct {
 	AccountContextParams
 	SignerParams
+	cascade       bool
 	claim         *jwt.AccountClaims
+	dangling      []DanglingImport
 	deletedExport *jwt.Export
 	index         int
 	subject       string
 }
 
+// DanglingImport is an import in another account that references
+// an export that is being removed
+type DanglingImport struct {
+	Account string
+	Import  *jwt.Import
+}
+
 func (p *DeleteExportParams) SetDefaults(ctx ActionCtx) error {
 	p.AccountContextParams.SetDefaults(ctx)
 	p.SignerParams.SetDefaults(nkeys.PrefixByteOperator, true, ctx)
@@ -115,6 +135,19 @@ func (p *DeleteExportParams) PostInteractive(ctx ActionCtx) error {
 		return err
 	}
 
+	if err = p.findDangling(ctx); err != nil {
+		return err
+	}
+	if len(p.dangling) > 0 && !p.cascade {
+		for _, v := range p.dangling {
+			ctx.CurrentCmd().Printf("account %q imports %q\n", v.Account, v.Import.Subject)
+		}
+		p.cascade, err = cli.PromptBoolean("delete the imports as well", true)
+		if err != nil {
+			return err
+		}
+	}
+
 	if err = p.SignerParams.Edit(ctx); err != nil {
 		return err
 	}
@@ -130,9 +163,44 @@ func (p *DeleteExportParams) Validate(ctx ActionCtx) error {
 	if p.index == -1 {
 		return fmt.Errorf("no export matching %q found", p.subject)
 	}
-	if p.SignerParams.Resolve(ctx); err != nil {
+	if err = p.SignerParams.Resolve(ctx); err != nil {
 		return err
 	}
+	if p.dangling == nil {
+		if err = p.findDangling(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findDangling collects all imports in the store that reference the selected export
+func (p *DeleteExportParams) findDangling(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+	export := p.claim.Exports[p.index]
+
+	accounts, err := s.ListSubContainers(store.Accounts)
+	if err != nil {
+		return err
+	}
+	p.dangling = []DanglingImport{}
+	for _, n := range accounts {
+		if n == p.AccountContextParams.Name {
+			continue
+		}
+		ac, err := s.ReadAccountClaim(n)
+		if err != nil {
+			return err
+		}
+		if ac == nil {
+			continue
+		}
+		for _, im := range ac.Imports {
+			if im.Account == p.claim.Subject && im.Subject.IsContainedIn(export.Subject) {
+				p.dangling = append(p.dangling, DanglingImport{Account: n, Import: im})
+			}
+		}
+	}
 	return nil
 }
 
@@ -144,5 +212,67 @@ func (p *DeleteExportParams) Run(ctx ActionCtx) error {
 	if err != nil {
 		return err
 	}
-	return ctx.StoreCtx().Store.StoreClaim([]byte(token))
+	if err := ctx.StoreCtx().Store.StoreClaim([]byte(token)); err != nil {
+		return err
+	}
+
+	if p.cascade {
+		return p.deleteDangling(ctx)
+	}
+	return nil
+}
+
+func (p *DeleteExportParams) deleteDangling(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+	affected := make(map[string][]*jwt.Import)
+	var names []string
+	for _, v := range p.dangling {
+		if _, ok := affected[v.Account]; !ok {
+			names = append(names, v.Account)
+		}
+		affected[v.Account] = append(affected[v.Account], v.Import)
+	}
+
+	for _, n := range names {
+		ac, err := s.ReadAccountClaim(n)
+		if err != nil {
+			return err
+		}
+		var imports jwt.Imports
+		for _, im := range ac.Imports {
+			if !containsImport(affected[n], im) {
+				imports.Add(im)
+			}
+		}
+		ac.Imports = imports
+
+		signer := p.signerKP
+		if p.SignerParams.kind != nkeys.PrefixByteOperator {
+			// managed stores self-sign, so each account signs its own claim
+			signer, err = ctx.StoreCtx().KeyStore.GetAccountKey(n)
+			if err != nil {
+				return err
+			}
+			if signer == nil {
+				return fmt.Errorf("unable to find the key for account %q", n)
+			}
+		}
+		token, err := ac.Encode(signer)
+		if err != nil {
+			return err
+		}
+		if err := s.StoreClaim([]byte(token)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsImport(imports []*jwt.Import, im *jwt.Import) bool {
+	for _, v := range imports {
+		if v.Account == im.Account && v.Subject == im.Subject && v.To == im.To {
+			return true
+		}
+	}
+	return false
 }
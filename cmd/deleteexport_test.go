@@ -60,3 +60,69 @@ func Test_DeleteExportInteractive(t *testing.T) {
 	require.NotNil(t, ac)
 	require.Len(t, ac.Exports, 1)
 }
+
+func Test_DeleteExportReportsDanglingImports(t *testing.T) {
+	ts := NewTestStore(t, "delete export")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo.>", false)
+	ts.AddAccount(t, "B")
+	ts.AddImport(t, "A", "foo.>", "B")
+
+	_, stderr, err := ExecuteCmd(createDeleteExportCmd(), "--account", "A", "--subject", "foo.>")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "account \"B\" still imports \"foo.>\"")
+
+	ac, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Len(t, ac.Imports, 1)
+}
+
+func Test_DeleteExportCascade(t *testing.T) {
+	ts := NewTestStore(t, "delete export")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo.>", false)
+	ts.AddExport(t, "A", jwt.Stream, "bar", false)
+	ts.AddAccount(t, "B")
+	ts.AddImport(t, "A", "foo.>", "B")
+	ts.AddImport(t, "A", "bar", "B")
+	ts.AddAccount(t, "C")
+	ts.AddImport(t, "A", "foo.>", "C")
+
+	_, stderr, err := ExecuteCmd(createDeleteExportCmd(), "--account", "A", "--subject", "foo.>", "--cascade")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Deleted import of \"foo.>\" from account \"B\"")
+	require.Contains(t, stderr, "Deleted import of \"foo.>\" from account \"C\"")
+
+	ac, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Len(t, ac.Imports, 1)
+	require.Equal(t, "bar", string(ac.Imports[0].Subject))
+	opk, err := ts.OperatorKey.PublicKey()
+	require.NoError(t, err)
+	require.Equal(t, opk, ac.Issuer)
+
+	ac, err = ts.Store.ReadAccountClaim("C")
+	require.NoError(t, err)
+	require.Len(t, ac.Imports, 0)
+}
+
+func Test_DeleteExportCascadeInteractive(t *testing.T) {
+	ts := NewTestStore(t, "delete export")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo", false)
+	ts.AddAccount(t, "B")
+	ts.AddImport(t, "A", "foo", "B")
+
+	input := []interface{}{0, 0, true}
+	cmd := createDeleteExportCmd()
+	HoistRootFlags(cmd)
+	_, _, err := ExecuteInteractiveCmd(cmd, input, "-i")
+	require.NoError(t, err)
+
+	ac, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Len(t, ac.Imports, 0)
+}
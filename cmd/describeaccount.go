@@ -40,6 +40,7 @@ func createDescribeAccountCmd() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&params.outputFile, "output-file", "o", "--", "output file, '--' is stdout")
+	cmd.Flags().BoolVarP(&params.offline, "offline", "", false, "only use cached copies of remote activations")
 	params.AccountContextParams.BindFlags(cmd)
 
 	return cmd
@@ -52,6 +53,7 @@ func init() {
 type DescribeAccountParams struct {
 	AccountContextParams
 	jwt.AccountClaims
+	offline    bool
 	outputFile string
 	token      string
 }
@@ -94,6 +96,7 @@ func (p *DescribeAccountParams) PostInteractive(ctx ActionCtx) error {
 }
 
 func (p *DescribeAccountParams) Run(ctx ActionCtx) error {
-	v := NewAccountDescriber(p.AccountClaims).Describe()
-	return Write(p.outputFile, []byte(v))
+	d := NewAccountDescriber(p.AccountClaims)
+	d.Cache = NewActivationCache(ctx.StoreCtx().Store, p.offline)
+	return Write(p.outputFile, []byte(d.Describe()))
 }
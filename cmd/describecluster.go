@@ -95,6 +95,11 @@ func (p *DescribeClusterParams) PostInteractive(ctx ActionCtx) error {
 }
 
 func (p *DescribeClusterParams) Run(ctx ActionCtx) error {
-	v := NewClusterDescriber(p.ClusterClaims).Describe()
-	return Write(p.outputFile, []byte(v))
+	d := NewClusterDescriber(p.ClusterClaims)
+	var err error
+	if d.AccountNames, err = AccountNames(ctx.StoreCtx().Store); err != nil {
+		return err
+	}
+	d.OperatorNames = OperatorNames()
+	return Write(p.outputFile, []byte(d.Describe()))
 }
@@ -108,3 +108,26 @@ func TestDescribeCluster_Interactive(t *testing.T) {
 	_, _, err := ExecuteInteractiveCmd(createDescribeClusterCmd(), []interface{}{0})
 	require.NoError(t, err)
 }
+
+func TestDescribeCluster_TrustNames(t *testing.T) {
+	ts := NewTestStore(t, "operator")
+	defer ts.Done(t)
+
+	ts.AddCluster(t, "C")
+	ts.AddAccount(t, "A")
+	_, foreign, _ := CreateAccountKey(t)
+	_, _, err := ExecuteCmd(createEditClusterCmd(), "--add-account", "A,"+foreign, "--add-operator", "operator")
+	require.NoError(t, err)
+
+	apk, err := ts.KeyStore.GetAccountPublicKey("A")
+	require.NoError(t, err)
+	opk, err := ts.KeyStore.GetOperatorPublicKey("operator")
+	require.NoError(t, err)
+
+	stdout, _, err := ExecuteCmd(createDescribeClusterCmd())
+	require.NoError(t, err)
+	require.Contains(t, stdout, apk+" (A)")
+	require.Contains(t, stdout, opk+" (operator)")
+	require.Contains(t, stdout, foreign)
+	require.NotContains(t, stdout, foreign+" (")
+}
@@ -17,10 +17,8 @@ package cmd
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/nats-io/jwt"
-	"github.com/nats-io/nsc/cmd/store"
 	"github.com/spf13/cobra"
 )
 
@@ -81,23 +79,10 @@ func (p *DescribeOperatorParams) PreInteractive(ctx ActionCtx) error {
 }
 
 func (p *DescribeOperatorParams) Load(ctx ActionCtx) error {
-	var err error
-
-	name := ctx.StoreCtx().Store.GetName()
-	if !ctx.StoreCtx().Store.Has(store.JwtName(name)) {
-		return fmt.Errorf("no operator %q found", name)
-	}
-
-	d, err := ctx.StoreCtx().Store.Read(store.JwtName(name))
-	if err != nil {
-		return err
-	}
-
-	oc, err := jwt.DecodeOperatorClaims(string(d))
+	oc, err := ReadOperatorClaim(ctx.StoreCtx().Store)
 	if err != nil {
 		return err
 	}
-
 	p.OperatorClaims = *oc
 	return nil
 }
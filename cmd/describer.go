@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/dustin/go-humanize"
 	"github.com/nats-io/jwt"
@@ -32,6 +33,7 @@ type Describer interface {
 
 type AccountDescriber struct {
 	jwt.AccountClaims
+	Cache *ActivationCache
 }
 
 func NewAccountDescriber(ac jwt.AccountClaims) *AccountDescriber {
@@ -105,7 +107,9 @@ func (a *AccountDescriber) Describe() string {
 
 	if len(a.Imports) > 0 {
 		buf.WriteString("\n")
-		buf.WriteString(NewImportsDescriber(a.Imports).Describe())
+		d := NewImportsDescriber(a.Imports)
+		d.Cache = a.Cache
+		buf.WriteString(d.Describe())
 	}
 
 	return buf.String()
@@ -139,6 +143,7 @@ func (e *ExportsDescriber) Describe() string {
 
 type ImportsDescriber struct {
 	jwt.Imports
+	Cache *ActivationCache
 }
 
 func NewImportsDescriber(imports jwt.Imports) *ImportsDescriber {
@@ -152,8 +157,27 @@ func (i *ImportsDescriber) Describe() string {
 	table.AddTitle("Imports")
 	table.AddHeaders("Type", "Subject", "To", "Expires")
 
-	for _, v := range i.Imports {
-		NewImportDescriber(*v).Brief(table)
+	// activations are resolved in parallel as remote ones may be slow to load
+	describers := make([]*ImportDescriber, len(i.Imports))
+	activations := make([]*jwt.ActivationClaims, len(i.Imports))
+	errs := make([]error, len(i.Imports))
+	var wg sync.WaitGroup
+	for idx, v := range i.Imports {
+		describers[idx] = NewImportDescriber(*v)
+		describers[idx].Cache = i.Cache
+		if v.Token == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			activations[idx], errs[idx] = describers[idx].LoadActivation()
+		}(idx)
+	}
+	wg.Wait()
+
+	for idx, d := range describers {
+		d.brief(table, activations[idx], errs[idx])
 	}
 
 	return table.Render()
@@ -161,19 +185,28 @@ func (i *ImportsDescriber) Describe() string {
 
 type ImportDescriber struct {
 	jwt.Import
+	Cache *ActivationCache
 }
 
 func NewImportDescriber(im jwt.Import) *ImportDescriber {
-	return &ImportDescriber{im}
+	return &ImportDescriber{Import: im}
 }
 
 func (i *ImportDescriber) Brief(table *tablewriter.Table) {
+	var ac *jwt.ActivationClaims
+	var err error
+	if i.Token != "" {
+		ac, err = i.LoadActivation()
+	}
+	i.brief(table, ac, err)
+}
+
+func (i *ImportDescriber) brief(table *tablewriter.Table, ac *jwt.ActivationClaims, err error) {
 	if i.Token == "" {
 		table.AddRow(strings.Title(i.Type.String()), string(i.Subject), string(i.To), "")
 		return
 	}
 	expiration := ""
-	ac, err := i.LoadActivation()
 	if err != nil {
 		expiration = fmt.Sprintf("error decoding: %v", err.Error())
 	} else {
@@ -191,7 +224,13 @@ func (i *ImportDescriber) IsRemoteImport() bool {
 
 func (i *ImportDescriber) LoadActivation() (*jwt.ActivationClaims, error) {
 	var token string
-	if i.IsRemoteImport() {
+	if i.IsRemoteImport() && i.Cache != nil {
+		t, err := i.Cache.Load(i.Token)
+		if err != nil {
+			return nil, err
+		}
+		token = t
+	} else if i.IsRemoteImport() {
 		d, err := LoadFromURL(i.Token)
 		if err != nil {
 			return nil, err
@@ -316,6 +355,9 @@ func (u *UserDescriber) Describe() string {
 
 type ClusterDescriber struct {
 	jwt.ClusterClaims
+	// AccountNames and OperatorNames map public keys to the names printed next to them
+	AccountNames  map[string]string
+	OperatorNames map[string]string
 }
 
 func NewClusterDescriber(c jwt.ClusterClaims) *ClusterDescriber {
@@ -330,12 +372,12 @@ func (c *ClusterDescriber) Describe() string {
 	table.AddRow("Cluster ID", c.Subject)
 	table.AddRow("Issuer ID", c.Issuer)
 
-	AddListValues(table, "Trusted Operators", c.Trust)
+	AddListValues(table, "Trusted Operators", withNames(c.Trust, c.OperatorNames))
 	if c.OperatorURL != "" {
 		table.AddRow("Operator Srv", c.OperatorURL)
 	}
 
-	AddListValues(table, "Trusted Accounts", c.Accounts)
+	AddListValues(table, "Trusted Accounts", withNames(c.Accounts, c.AccountNames))
 	if c.AccountURL != "" {
 		table.AddRow("Account Srv", c.AccountURL)
 	}
@@ -354,6 +396,9 @@ func (c *ClusterDescriber) Describe() string {
 
 type ServerDescriber struct {
 	jwt.ServerClaims
+	// StaleCluster is set if the embedded cluster JWT is not the JWT of the cluster
+	StaleCluster   bool
+	ClusterChanges []ClusterChange
 }
 
 func NewServerDescriber(u jwt.ServerClaims) *ServerDescriber {
@@ -376,9 +421,23 @@ func (s *ServerDescriber) Describe() string {
 	}
 	AddListValues(table, "Tags", s.Tags)
 
+	if s.StaleCluster {
+		table.AddRow("Cluster JWT", "Stale - re-issue with edit server")
+		for _, c := range s.ClusterChanges {
+			table.AddRow(fmt.Sprintf("  %s", c.Field), fmt.Sprintf("%s -> %s", valueOrNone(c.From), valueOrNone(c.To)))
+		}
+	}
+
 	return table.Render()
 }
 
+func valueOrNone(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return v
+}
+
 type OperatorDescriber struct {
 	jwt.OperatorClaims
 }
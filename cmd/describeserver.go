@@ -57,6 +57,8 @@ type DescribeServerParams struct {
 	server     string
 	outputFile string
 	token      string
+	stale      bool
+	changes    []ClusterChange
 }
 
 func (p *DescribeServerParams) SetDefaults(ctx ActionCtx) error {
@@ -98,6 +100,10 @@ func (p *DescribeServerParams) Load(ctx ActionCtx) error {
 		return fmt.Errorf("server is required")
 	}
 
+	if p.server, err = ctx.StoreCtx().Store.ResolveServer(p.ClusterContextParams.Name, p.server); err != nil {
+		return err
+	}
+
 	if !ctx.StoreCtx().Store.Has(store.Clusters, p.ClusterContextParams.Name, store.Servers, store.JwtName(p.server)) {
 		return fmt.Errorf("server %q not found", p.server)
 	}
@@ -108,8 +114,9 @@ func (p *DescribeServerParams) Load(ctx ActionCtx) error {
 	}
 	if us != nil {
 		p.ServerClaims = *us
+		p.stale, p.changes, err = staleCluster(ctx.StoreCtx().Store, p.ClusterContextParams.Name, us)
 	}
-	return nil
+	return err
 }
 
 func (p *DescribeServerParams) Validate(ctx ActionCtx) error {
@@ -121,6 +128,8 @@ func (p *DescribeServerParams) PostInteractive(ctx ActionCtx) error {
 }
 
 func (p *DescribeServerParams) Run(ctx ActionCtx) error {
-	v := NewServerDescriber(p.ServerClaims).Describe()
-	return Write(p.outputFile, []byte(v))
+	d := NewServerDescriber(p.ServerClaims)
+	d.StaleCluster = p.stale
+	d.ClusterChanges = p.changes
+	return Write(p.outputFile, []byte(d.Describe()))
 }
@@ -136,3 +136,34 @@ func TestDescribeServer_Interactive(t *testing.T) {
 	_, _, err := ExecuteInteractiveCmd(createDescribeServerCmd(), []interface{}{1, 0})
 	require.NoError(t, err)
 }
+
+func TestDescribeServer_StaleCluster(t *testing.T) {
+	ts := NewTestStore(t, "operator")
+	defer ts.Done(t)
+
+	ts.AddServer(t, "C", "s")
+
+	stdout, _, err := ExecuteCmd(createDescribeServerCmd())
+	require.NoError(t, err)
+	require.NotContains(t, stdout, "Stale")
+
+	// the cluster changes without its servers
+	cc, err := ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	cc.Tags.Add("east")
+	token, err := cc.Encode(ts.OperatorKey)
+	require.NoError(t, err)
+	require.NoError(t, ts.Store.StoreClaim([]byte(token)))
+
+	stdout, _, err = ExecuteCmd(createDescribeServerCmd())
+	require.NoError(t, err)
+	stdout = StripTableDecorations(stdout)
+	require.Contains(t, stdout, "Cluster JWT Stale")
+	require.Contains(t, stdout, "Tags none -> east")
+
+	_, _, err = ExecuteCmd(createEditServerCmd(), "--tag", "a")
+	require.NoError(t, err)
+	stdout, _, err = ExecuteCmd(createDescribeServerCmd())
+	require.NoError(t, err)
+	require.NotContains(t, stdout, "Stale")
+}
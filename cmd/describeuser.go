@@ -96,6 +96,10 @@ func (p *DescribeUserParams) Load(ctx ActionCtx) error {
 		return fmt.Errorf("user is required")
 	}
 
+	if p.user, err = ctx.StoreCtx().Store.ResolveUser(p.AccountContextParams.Name, p.user); err != nil {
+		return err
+	}
+
 	if !ctx.StoreCtx().Store.Has(store.Accounts, p.AccountContextParams.Name, store.Users, store.JwtName(p.user)) {
 		return fmt.Errorf("user %q not found", p.user)
 	}
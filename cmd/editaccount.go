@@ -112,6 +112,9 @@ func (p *EditAccountParams) Load(ctx ActionCtx) error {
 	if err != nil {
 		return err
 	}
+	if p.claim == nil {
+		return fmt.Errorf("account %q not found", p.AccountContextParams.Name)
+	}
 	return err
 }
 
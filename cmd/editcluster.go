@@ -27,8 +27,11 @@ import (
 func createEditClusterCmd() *cobra.Command {
 	var params EditClusterParams
 	cmd := &cobra.Command{
-		Use:          "cluster",
-		Short:        "Edit a cluster",
+		Use:   "cluster",
+		Short: "Edit a cluster",
+		Example: `nsc edit cluster --add-account A,B --add-operator O
+nsc edit cluster --rm-account B
+nsc edit cluster --all-accounts`,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := RunAction(cmd, args, &params); err != nil {
@@ -49,6 +52,7 @@ func createEditClusterCmd() *cobra.Command {
 					UnixToDate(params.claim.Expires),
 					HumanizedDate(params.claim.Expires))
 			}
+			params.sync.Print(cmd)
 
 			return nil
 		},
@@ -56,8 +60,13 @@ func createEditClusterCmd() *cobra.Command {
 	cmd.Flags().StringSliceVarP(&params.tags, "tag", "", nil, "add tags for user - comma separated list or option can be specified multiple times")
 	cmd.Flags().StringSliceVarP(&params.rmTags, "rm-tag", "", nil, "remove tag - comma separated list or option can be specified multiple times")
 
-	cmd.Flags().StringSliceVar(&params.accounts, "trusted-accounts", nil, "set trusted account public keys")
-	cmd.Flags().StringSliceVar(&params.operators, "trusted-operators", nil, "set trusted operator public keys")
+	cmd.Flags().StringSliceVar(&params.accounts, "trusted-accounts", nil, "set trusted account names or public keys")
+	cmd.Flags().StringSliceVar(&params.operators, "trusted-operators", nil, "set trusted operator names or public keys")
+	cmd.Flags().StringSliceVarP(&params.addAccounts, "add-account", "", nil, "add trusted account names or public keys - comma separated list or option can be specified multiple times")
+	cmd.Flags().StringSliceVarP(&params.rmAccounts, "rm-account", "", nil, "remove trusted account names or public keys - comma separated list or option can be specified multiple times")
+	cmd.Flags().StringSliceVarP(&params.addOperators, "add-operator", "", nil, "add trusted operator names or public keys - comma separated list or option can be specified multiple times")
+	cmd.Flags().StringSliceVarP(&params.rmOperators, "rm-operator", "", nil, "remove trusted operator names or public keys - comma separated list or option can be specified multiple times")
+	cmd.Flags().BoolVarP(&params.allAccounts, "all-accounts", "", false, "trust all the accounts in the store")
 	cmd.Flags().StringVar(&params.accountUrlTemplate, "account-url-template", "", "template url for retrieving account jwts by account id")
 	cmd.Flags().StringVar(&params.operatorUrlTemplate, "operator-url-template", "", "template url for retrieving operator jwts by operator id")
 
@@ -78,9 +87,15 @@ type EditClusterParams struct {
 	claim               *jwt.ClusterClaims
 	accountUrlTemplate  string
 	accounts            []string
+	addAccounts         []string
+	rmAccounts          []string
+	allAccounts         bool
 	operatorUrlTemplate string
 	operators           []string
+	addOperators        []string
+	rmOperators         []string
 	token               string
+	sync                *ServerSync
 	tags                []string
 	rmTags              []string
 }
@@ -89,11 +104,15 @@ func (p *EditClusterParams) SetDefaults(ctx ActionCtx) error {
 	p.ClusterContextParams.SetDefaults(ctx)
 	p.SignerParams.SetDefaults(nkeys.PrefixByteOperator, true, ctx)
 
-	if !InteractiveFlag && ctx.NothingToDo("start", "expiry", "trusted-accounts",
-		"trusted-operators", "account-url-template", "operator-url-template", "tag", "rm-tag") {
+	if !InteractiveFlag && ctx.NothingToDo("start", "expiry", "trusted-accounts", "add-account", "rm-account", "all-accounts",
+		"trusted-operators", "add-operator", "rm-operator", "account-url-template", "operator-url-template", "tag", "rm-tag") {
 		ctx.CurrentCmd().SilenceUsage = false
 		return fmt.Errorf("specify an edit option")
 	}
+	if p.allAccounts && ctx.CurrentCmd().Flag("trusted-accounts").Changed {
+		ctx.CurrentCmd().SilenceUsage = false
+		return fmt.Errorf("--all-accounts and --trusted-accounts are exclusive")
+	}
 	return nil
 }
 
@@ -139,9 +158,61 @@ func (p *EditClusterParams) Validate(ctx ActionCtx) error {
 	if err = p.SignerParams.Resolve(ctx); err != nil {
 		return err
 	}
+
+	s := ctx.StoreCtx().Store
+	if p.accounts, err = resolveTrustedAccounts(s, p.accounts); err != nil {
+		return err
+	}
+	if p.addAccounts, err = resolveTrustedAccounts(s, p.addAccounts); err != nil {
+		return err
+	}
+	if p.rmAccounts, err = p.resolveRemoved(p.claim.Accounts, p.rmAccounts, func(v []string) ([]string, error) {
+		return resolveTrustedAccounts(s, v)
+	}); err != nil {
+		return err
+	}
+	if p.operators, err = resolveTrustedOperators(p.operators); err != nil {
+		return err
+	}
+	if p.addOperators, err = resolveTrustedOperators(p.addOperators); err != nil {
+		return err
+	}
+	if p.rmOperators, err = p.resolveRemoved(p.claim.Trust, p.rmOperators, resolveTrustedOperators); err != nil {
+		return err
+	}
+	if p.allAccounts {
+		names, err := AccountNames(s)
+		if err != nil {
+			return err
+		}
+		p.accounts = nil
+		for pk := range names {
+			p.accounts = append(p.accounts, pk)
+		}
+	}
 	return nil
 }
 
+// resolveRemoved resolves the values to remove, values already in the list
+// are removed as is so keys of entities that are gone can be removed
+func (p *EditClusterParams) resolveRemoved(current []string, values []string, resolve func([]string) ([]string, error)) ([]string, error) {
+	var list jwt.StringList
+	list.Add(current...)
+	var keys []string
+	for _, v := range values {
+		if list.Contains(v) {
+			keys = append(keys, v)
+			continue
+		}
+		pk, err := resolve([]string{v})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, pk...)
+	}
+	return keys, nil
+}
+
 func (p *EditClusterParams) Run(ctx ActionCtx) error {
 	var err error
 	if p.TimeParams.IsStartChanged() {
@@ -160,15 +231,28 @@ func (p *EditClusterParams) Run(ctx ActionCtx) error {
 		p.claim.OperatorURL = p.operatorUrlTemplate
 	}
 
-	if ctx.CurrentCmd().Flag("trusted-accounts").Changed {
-		var accounts jwt.StringList
-		accounts.Add(p.accounts...)
+	// the trust lists are only rewritten when changed, so other edits keep them as is
+	if p.allAccounts || !ctx.NothingToDo("trusted-accounts", "add-account", "rm-account") {
+		accounts := jwt.StringList(p.claim.Accounts)
+		if ctx.CurrentCmd().Flag("trusted-accounts").Changed || p.allAccounts {
+			accounts = nil
+			accounts.Add(p.accounts...)
+		}
+		accounts.Add(p.addAccounts...)
+		accounts.Remove(p.rmAccounts...)
+		sort.Strings(accounts)
 		p.claim.Accounts = accounts
 	}
 
-	if ctx.CurrentCmd().Flag("trusted-operators").Changed {
-		var operators jwt.StringList
-		operators.Add(p.operators...)
+	if !ctx.NothingToDo("trusted-operators", "add-operator", "rm-operator") {
+		operators := jwt.StringList(p.claim.Trust)
+		if ctx.CurrentCmd().Flag("trusted-operators").Changed {
+			operators = nil
+			operators.Add(p.operators...)
+		}
+		operators.Add(p.addOperators...)
+		operators.Remove(p.rmOperators...)
+		sort.Strings(operators)
 		p.claim.Trust = operators
 	}
 
@@ -180,5 +264,9 @@ func (p *EditClusterParams) Run(ctx ActionCtx) error {
 	if err != nil {
 		return err
 	}
-	return ctx.StoreCtx().Store.StoreClaim([]byte(p.token))
+	if err = ctx.StoreCtx().Store.StoreClaim([]byte(p.token)); err != nil {
+		return err
+	}
+	p.sync, err = SyncServers(ctx, p.ClusterContextParams.Name, p.token)
+	return err
 }
@@ -16,8 +16,13 @@
 package cmd
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
 	"github.com/stretchr/testify/require"
 )
 
@@ -181,3 +186,144 @@ func Test_EditCluster_OperatorURL(t *testing.T) {
 	require.NotNil(t, cc)
 	require.Equal(t, "", cc.OperatorURL)
 }
+
+func Test_EditCluster_TrustByName(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	ts.AddCluster(t, "C")
+	ts.AddAccount(t, "A")
+	ts.AddAccount(t, "B")
+	apk, err := ts.KeyStore.GetAccountPublicKey("A")
+	require.NoError(t, err)
+	bpk, err := ts.KeyStore.GetAccountPublicKey("B")
+	require.NoError(t, err)
+	opk, err := ts.OperatorKey.PublicKey()
+	require.NoError(t, err)
+
+	_, _, err = ExecuteCmd(createEditClusterCmd(), "--add-account", "A", "--add-operator", "O")
+	require.NoError(t, err)
+	cc, err := ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	require.Equal(t, []string{apk}, cc.Accounts)
+	require.Equal(t, []string{opk}, cc.Trust)
+
+	_, _, err = ExecuteCmd(createEditClusterCmd(), "--add-account", bpk[:6])
+	require.NoError(t, err)
+	cc, err = ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{apk, bpk}, cc.Accounts)
+
+	_, _, err = ExecuteCmd(createEditClusterCmd(), "--rm-account", "A", "--rm-operator", "O")
+	require.NoError(t, err)
+	cc, err = ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	require.Equal(t, []string{bpk}, cc.Accounts)
+	require.Len(t, cc.Trust, 0)
+
+	_, _, err = ExecuteCmd(createEditClusterCmd(), "--add-account", "X")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "\"X\" is not an account public key or an account in the store")
+	_, _, err = ExecuteCmd(createEditClusterCmd(), "--add-operator", "X")
+	require.Error(t, err)
+}
+
+func Test_EditCluster_AllAccounts(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	ts.AddCluster(t, "C")
+	ts.AddAccount(t, "A")
+	ts.AddAccount(t, "B")
+	_, foreign, _ := CreateAccountKey(t)
+
+	_, _, err := ExecuteCmd(createEditClusterCmd(), "--add-account", foreign)
+	require.NoError(t, err)
+
+	_, _, err = ExecuteCmd(createEditClusterCmd(), "--all-accounts")
+	require.NoError(t, err)
+	cc, err := ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	names, err := AccountNames(ts.Store)
+	require.NoError(t, err)
+	require.Len(t, cc.Accounts, 2)
+	for _, pk := range cc.Accounts {
+		require.Contains(t, names, pk)
+	}
+
+	_, _, err = ExecuteCmd(createEditClusterCmd(), "--all-accounts", "--trusted-accounts", foreign)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exclusive")
+}
+
+func Test_EditCluster_ReissuesServers(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	ts.AddServer(t, "C", "s1")
+	ts.AddServer(t, "C", "s2")
+
+	_, stderr, err := ExecuteCmd(createEditClusterCmd(), "--tag", "prod")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Re-issued server \"s1\"")
+	require.Contains(t, stderr, "Re-issued server \"s2\"")
+
+	d, err := ts.Store.Read(store.Clusters, "C", store.JwtName("C"))
+	require.NoError(t, err)
+	for _, n := range []string{"s1", "s2"} {
+		sc, err := ts.Store.ReadServerClaim("C", n)
+		require.NoError(t, err)
+		require.Equal(t, string(d), sc.Cluster)
+	}
+
+	// without the cluster seed the servers are reported
+	require.NoError(t, os.Remove(ts.KeyStore.KeyPath(nkeys.PrefixByteCluster, "", "C")))
+	_, stderr, err = ExecuteCmd(createEditClusterCmd(), "--tag", "east")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Server \"s1\" has a stale cluster JWT")
+	sc, err := ts.Store.ReadServerClaim("C", "s1")
+	require.NoError(t, err)
+	require.Equal(t, string(d), sc.Cluster)
+}
+
+func Test_EditCluster_KeepsTrustOrder(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	ts.AddCluster(t, "C")
+	_, apk, _ := CreateAccountKey(t)
+	_, bpk, _ := CreateAccountKey(t)
+	if apk < bpk {
+		apk, bpk = bpk, apk
+	}
+	cc, err := ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	cc.Accounts = []string{apk, bpk}
+	token, err := cc.Encode(ts.OperatorKey)
+	require.NoError(t, err)
+	require.NoError(t, ts.Store.StoreClaim([]byte(token)))
+
+	_, _, err = ExecuteCmd(createEditClusterCmd(), "--tag", "T")
+	require.NoError(t, err)
+	cc, err = ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	require.Equal(t, []string{apk, bpk}, cc.Accounts)
+}
+
+func Test_EditCluster_TrustSkipsBrokenStores(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	ts.AddCluster(t, "C")
+	broken := filepath.Join(ts.GetStoresRoot(), "broken")
+	require.NoError(t, os.MkdirAll(broken, 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(broken, store.NSCFile), []byte("{"), 0600))
+
+	_, _, err := ExecuteCmd(createEditClusterCmd(), "--add-operator", "O")
+	require.NoError(t, err)
+	opk, err := ts.OperatorKey.PublicKey()
+	require.NoError(t, err)
+	cc, err := ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	require.Equal(t, []string{opk}, cc.Trust)
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+)
+
+func createEditOperatorCmd() *cobra.Command {
+	var params EditOperatorParams
+	cmd := &cobra.Command{
+		Use:   "operator",
+		Short: "Edit the operator",
+		Long: `Edit the operator
+
+An identity associates the operator with a domain or a document the operator
+controls. The ID names where the proof is published, such as dns:example.com
+for a TXT record or the URL of a document. The proof is the signature of the
+ID by the operator key:
+
+  echo -n dns:example.com > id.txt
+  nsc nkey sign --key operator.nk --file id.txt
+
+Publish the proof and check it with 'nsc verify operator'.`,
+		Example: `nsc edit operator --add-identity dns:example.com=kN3N...
+nsc edit operator --rm-identity dns:example.com`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+
+			cmd.Printf("Success! - edited operator %q\n", params.claim.Name)
+
+			_ = Write("--", FormatJwt("Operator", params.token))
+
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVarP(&params.identities, "add-identity", "", nil, "add an identity as <id>=<proof> - option can be specified multiple times")
+	cmd.Flags().StringSliceVarP(&params.rmIdentities, "rm-identity", "", nil, "remove an identity by id - comma separated list or option can be specified multiple times")
+
+	return cmd
+}
+
+func init() {
+	editCmd.AddCommand(createEditOperatorCmd())
+}
+
+type EditOperatorParams struct {
+	SignerParams
+	claim        *jwt.OperatorClaims
+	token        string
+	identities   []string
+	rmIdentities []string
+	add          []jwt.Identity
+}
+
+func (p *EditOperatorParams) SetDefaults(ctx ActionCtx) error {
+	p.SignerParams.SetDefaults(nkeys.PrefixByteOperator, false, ctx)
+
+	if !InteractiveFlag && ctx.NothingToDo("add-identity", "rm-identity") {
+		ctx.CurrentCmd().SilenceUsage = false
+		return fmt.Errorf("specify an edit option")
+	}
+	return nil
+}
+
+func (p *EditOperatorParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *EditOperatorParams) Load(ctx ActionCtx) error {
+	var err error
+	p.claim, err = ReadOperatorClaim(ctx.StoreCtx().Store)
+	return err
+}
+
+func (p *EditOperatorParams) PostInteractive(ctx ActionCtx) error {
+	return p.SignerParams.Edit(ctx)
+}
+
+func (p *EditOperatorParams) Validate(ctx ActionCtx) error {
+	for _, v := range p.identities {
+		id, err := ParseIdentity(v)
+		if err != nil {
+			return err
+		}
+		if err := VerifyIdentityProof(p.claim.Subject, id); err != nil {
+			return err
+		}
+		p.add = append(p.add, id)
+	}
+	for _, v := range p.rmIdentities {
+		if findIdentity(p.claim.Identities, v) == -1 {
+			return fmt.Errorf("operator doesn't have an identity %q", v)
+		}
+	}
+
+	if err := p.SignerParams.Resolve(ctx); err != nil {
+		return err
+	}
+	if p.signerKP == nil {
+		return fmt.Errorf("the operator key is required to edit the operator")
+	}
+	if !store.Match(p.claim.Issuer, p.signerKP) {
+		return fmt.Errorf("the operator key doesn't match the issuer of the operator")
+	}
+	return nil
+}
+
+func (p *EditOperatorParams) Run(ctx ActionCtx) error {
+	var err error
+	for _, v := range p.rmIdentities {
+		if i := findIdentity(p.claim.Identities, v); i != -1 {
+			p.claim.Identities = append(p.claim.Identities[:i], p.claim.Identities[i+1:]...)
+		}
+	}
+	for _, id := range p.add {
+		if i := findIdentity(p.claim.Identities, id.ID); i != -1 {
+			p.claim.Identities[i] = id
+		} else {
+			p.claim.Identities = append(p.claim.Identities, id)
+		}
+	}
+
+	p.token, err = p.claim.Encode(p.signerKP)
+	if err != nil {
+		return err
+	}
+	return ctx.StoreCtx().Store.StoreClaim([]byte(p.token))
+}
+
+// ReadOperatorClaim reads the operator JWT of the store
+func ReadOperatorClaim(s *store.Store) (*jwt.OperatorClaims, error) {
+	name := s.GetName()
+	if !s.Has(store.JwtName(name)) {
+		return nil, fmt.Errorf("no operator %q found", name)
+	}
+	d, err := s.Read(store.JwtName(name))
+	if err != nil {
+		return nil, err
+	}
+	return jwt.DecodeOperatorClaims(string(d))
+}
+
+// ParseIdentity parses an identity in the <id>=<proof> format
+func ParseIdentity(v string) (jwt.Identity, error) {
+	var id jwt.Identity
+	i := strings.Index(v, "=")
+	if i == -1 {
+		return id, fmt.Errorf("identity %q is not in the <id>=<proof> format", v)
+	}
+	id.ID = strings.TrimSpace(v[:i])
+	id.Proof = strings.TrimSpace(v[i+1:])
+	if id.ID == "" || strings.IndexFunc(id.ID, unicode.IsSpace) != -1 {
+		return id, fmt.Errorf("identity id %q can't be empty or contain spaces", id.ID)
+	}
+	if id.Proof == "" {
+		return id, fmt.Errorf("identity %q doesn't have a proof", id.ID)
+	}
+	return id, nil
+}
+
+// VerifyIdentityProof checks that the proof is the signature of the ID by the operator
+func VerifyIdentityProof(operator string, id jwt.Identity) error {
+	sig, err := DecodeSignature(id.Proof)
+	if err != nil {
+		return fmt.Errorf("proof of identity %q: %v", id.ID, err)
+	}
+	kp, err := nkeys.FromPublicKey(operator)
+	if err != nil {
+		return err
+	}
+	if err := kp.Verify([]byte(id.ID), sig); err != nil {
+		return fmt.Errorf("proof of identity %q is not signed by the operator", id.ID)
+	}
+	return nil
+}
+
+func findIdentity(identities []jwt.Identity, id string) int {
+	for i, v := range identities {
+		if v.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+// identityProof signs the id with the key of the operator of the test store
+func identityProof(t *testing.T, ts *TestStore, id string) string {
+	kp, err := ts.KeyStore.GetOperatorKey("operator")
+	require.NoError(t, err)
+	sig, err := kp.Sign([]byte(id))
+	require.NoError(t, err)
+	return EncodeSignature(sig)
+}
+
+func Test_EditOperator(t *testing.T) {
+	ts := NewTestStore(t, "operator")
+	defer ts.Done(t)
+
+	tests := CmdTests{
+		{createEditOperatorCmd(), []string{"edit", "operator"}, nil, []string{"specify an edit option"}, true},
+		{createEditOperatorCmd(), []string{"edit", "operator", "--add-identity", "dns:example.com"}, nil, []string{"is not in the <id>=<proof> format"}, true},
+		{createEditOperatorCmd(), []string{"edit", "operator", "--add-identity", "dns:example.com="}, nil, []string{"doesn't have a proof"}, true},
+		{createEditOperatorCmd(), []string{"edit", "operator", "--add-identity", "dns:example.com=bad*proof"}, nil, []string{"not base64 encoded"}, true},
+		{createEditOperatorCmd(), []string{"edit", "operator", "--add-identity", fmt.Sprintf("dns:example.com=%s", identityProof(t, ts, "dns:example.org"))}, nil, []string{"is not signed by the operator"}, true},
+		{createEditOperatorCmd(), []string{"edit", "operator", "--rm-identity", "dns:example.com"}, nil, []string{"doesn't have an identity"}, true},
+	}
+
+	tests.Run(t, "root", "edit")
+}
+
+func Test_EditOperatorIdentities(t *testing.T) {
+	ts := NewTestStore(t, "operator")
+	defer ts.Done(t)
+
+	a := fmt.Sprintf("dns:example.com=%s", identityProof(t, ts, "dns:example.com"))
+	b := fmt.Sprintf("https://example.com/nats.txt=%s", identityProof(t, ts, "https://example.com/nats.txt"))
+	_, _, err := ExecuteCmd(createEditOperatorCmd(), "--add-identity", a, "--add-identity", b)
+	require.NoError(t, err)
+
+	oc, err := ReadOperatorClaim(ts.Store)
+	require.NoError(t, err)
+	require.Len(t, oc.Identities, 2)
+	require.Equal(t, jwt.Identity{ID: "dns:example.com", Proof: identityProof(t, ts, "dns:example.com")}, oc.Identities[0])
+	require.Equal(t, "https://example.com/nats.txt", oc.Identities[1].ID)
+
+	stdout, _, err := ExecuteCmd(createDescribeOperatorCmd())
+	require.NoError(t, err)
+	require.Contains(t, stdout, "ID dns:example.com")
+
+	_, _, err = ExecuteCmd(createEditOperatorCmd(), "--rm-identity", "dns:example.com")
+	require.NoError(t, err)
+
+	oc, err = ReadOperatorClaim(ts.Store)
+	require.NoError(t, err)
+	require.Len(t, oc.Identities, 1)
+	require.Equal(t, "https://example.com/nats.txt", oc.Identities[0].ID)
+}
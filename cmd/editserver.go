@@ -137,6 +137,10 @@ func (p *EditServerParams) Load(ctx ActionCtx) error {
 		return fmt.Errorf("server name is required")
 	}
 
+	if p.name, err = ctx.StoreCtx().Store.ResolveServer(p.ClusterContextParams.Name, p.name); err != nil {
+		return err
+	}
+
 	if !ctx.StoreCtx().Store.Has(store.Clusters, p.ClusterContextParams.Name, store.Servers, store.JwtName(p.name)) {
 		return fmt.Errorf("server %q not found", p.name)
 	}
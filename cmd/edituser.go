@@ -163,6 +163,10 @@ func (p *EditUserParams) Load(ctx ActionCtx) error {
 		return fmt.Errorf("user name is required")
 	}
 
+	if p.name, err = ctx.StoreCtx().Store.ResolveUser(p.AccountContextParams.Name, p.name); err != nil {
+		return err
+	}
+
 	if !ctx.StoreCtx().Store.Has(store.Accounts, p.AccountContextParams.Name, store.Users, store.JwtName(p.name)) {
 		return fmt.Errorf("user %q not found", p.name)
 	}
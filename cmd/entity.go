@@ -39,6 +39,9 @@ type Entity struct {
 
 func (c *Entity) Valid() error {
 	var err error
+	if err = store.ValidateName(c.kind.String(), c.name); err != nil {
+		return err
+	}
 	if c.keyPath != "" {
 		c.kp, err = store.ResolveKey(c.keyPath)
 		if err != nil {
@@ -116,7 +119,9 @@ func (c *Entity) Edit() error {
 	var err error
 	label := c.kind.String()
 
-	c.name, err = cli.Prompt(fmt.Sprintf("%s name", label), c.name, true, cli.LengthValidator(1))
+	c.name, err = cli.Prompt(fmt.Sprintf("%s name", label), c.name, true, func(v string) error {
+		return store.ValidateName(label, v)
+	})
 	if err != nil {
 		return err
 	}
@@ -174,6 +179,10 @@ func (c *Entity) GenerateClaim(signer nkeys.KeyPair, ctx ActionCtx) error {
 	if err != nil {
 		return err
 	}
+	// write through the store of the action so the change is audited
+	if ctx != nil && ctx.StoreCtx() != nil {
+		return ctx.StoreCtx().Store.StoreClaim([]byte(token))
+	}
 	s, err := GetStore()
 	if err != nil {
 		return err
@@ -73,7 +73,28 @@ func (p *SetContextParams) Run() error {
 	}
 
 	current := GetConfig()
+	c, err := p.ContextConfig(&current.ContextConfig)
+	if err != nil {
+		return err
+	}
+	current.ContextConfig = *c
+	// edits apply to the named context in use
+	if current.CurrentContext != "" {
+		current.Contexts[current.CurrentContext] = *c
+	}
+	// explicit edits are saved even if the context was selected by --context or discovered
+	savedContext = nil
+	storeSource = defaultStoreSource
+	if current.CurrentContext != "" {
+		storeSource = fmt.Sprintf("context %q", current.CurrentContext)
+	}
+	clearContextSources()
+
+	return current.Save()
+}
 
+// ContextConfig returns the context that results from applying the params to the current context
+func (p *SetContextParams) ContextConfig(current *ContextConfig) (*ContextConfig, error) {
 	root := current.StoreRoot
 	if p.StoreRoot != "" {
 		root = p.StoreRoot
@@ -81,26 +102,24 @@ func (p *SetContextParams) Run() error {
 
 	c, err := NewContextConfig(root)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if p.Operator != "" {
 		if err := c.SetOperator(p.Operator); err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if p.Account != "" {
 		if err := c.SetAccount(p.Account); err != nil {
-			return err
+			return nil, err
 		}
 	}
 	if p.Cluster != "" {
 		if err := c.SetCluster(p.Cluster); err != nil {
-			return err
+			return nil, err
 		}
 	}
-	current.ContextConfig = *c
-
-	return current.Save()
+	return c, nil
 }
 
 func (p *SetContextParams) PrintEnv(cmd *cobra.Command) {
@@ -110,17 +129,31 @@ func (p *SetContextParams) PrintEnv(cmd *cobra.Command) {
 	table := tablewriter.CreateTable()
 	table.UTF8Box()
 	table.AddTitle("NSC Environment")
-	table.AddHeaders("Setting", "Set", "Effective Value")
-	table.AddRow("$"+store.NKeysPathEnv, envSet(store.NKeysPathEnv), store.GetKeysDir())
-	table.AddRow("$"+homeEnv, envSet(homeEnv), toolHome)
+	table.AddHeaders("Setting", "Set", "Effective Value", "Source")
+	table.AddRow("$"+store.NKeysPathEnv, envSet(store.NKeysPathEnv), store.GetKeysDir(), "")
+	table.AddRow("$"+homeEnv, envSet(homeEnv), toolHome, "")
+	if conf.DisableUpdateChecks {
+		table.AddRow("Update Checks", "Yes", "disabled", "")
+	} else {
+		table.AddRow("Update Checks", "", "daily", "")
+	}
+	if configViper != nil && configViper.ConfigFileUsed() != "" {
+		table.AddRow("Config File", "Yes", configViper.ConfigFileUsed(), "")
+	}
 	table.AddSeparator()
+	if conf.CurrentContext != "" {
+		table.AddRow("Current Context", "", conf.CurrentContext, "")
+	}
 	if s == nil {
-		table.AddRow("Stores Dir", "", "not set")
+		table.AddRow("Stores Dir", "", "not set", SettingSource(StoreSetting))
 	} else {
-		table.AddRow("Stores Dir", "", conf.StoreRoot)
-		table.AddRow("Default Operator", "", conf.Operator)
-		table.AddRow("Default Account", "", conf.Account)
-		table.AddRow("Default Cluster", "", conf.Cluster)
+		table.AddRow("Stores Dir", "", conf.StoreRoot, SettingSource(StoreSetting))
+		table.AddRow("Default Operator", "", conf.Operator, SettingSource(OperatorSetting))
+		table.AddRow("Default Account", "", conf.Account, SettingSource(AccountSetting))
+		table.AddRow("Default Cluster", "", conf.Cluster, SettingSource(ClusterSetting))
+	}
+	if KeyPathFlag != "" {
+		table.AddRow("Private Key", "", KeyPathFlag, SettingSource(PrivateKeySetting))
 	}
 	cmd.Println(table.Render())
 }
@@ -0,0 +1,256 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func createExportSpecCmd() *cobra.Command {
+	var params ExportSpecParams
+	cmd := &cobra.Command{
+		Use:   "spec",
+		Short: "Export the operator as a spec that can be applied with apply",
+		Long: `Export the operator as a spec that can be applied with apply
+
+The spec describes the accounts, users, clusters and servers of the operator,
+with their limits, exports, imports, permissions and trust. Entities are
+referenced by their public keys, seeds are never exported.`,
+		Example: `nsc export spec
+nsc export spec --json --output-file spec.json
+nsc export spec --all-operators`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			if !IsStdOut(params.out) {
+				cmd.Printf("Success! - wrote spec to %q\n", params.out)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.out, "output-file", "o", "--", "output file '--' is stdout")
+	cmd.Flags().BoolVarP(&params.json, "json", "", false, "export JSON instead of YAML")
+	cmd.Flags().BoolVarP(&params.all, "all-operators", "", false, "export all the operators in the stores directory")
+
+	return cmd
+}
+
+func init() {
+	exportCmd.AddCommand(createExportSpecCmd())
+}
+
+type ExportSpecParams struct {
+	all  bool
+	json bool
+	out  string
+	spec Spec
+}
+
+func (p *ExportSpecParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ExportSpecParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ExportSpecParams) Load(ctx ActionCtx) error {
+	stores := []*store.Store{ctx.StoreCtx().Store}
+	if p.all {
+		stores = nil
+		config := GetConfig()
+		for _, n := range config.ListOperators() {
+			s, err := config.LoadStore(n)
+			if err != nil {
+				return err
+			}
+			stores = append(stores, s)
+		}
+	}
+
+	// trusted operators are referenced by name when they are in the stores directory
+	operators := OperatorNames()
+
+	for _, s := range stores {
+		o, err := OperatorSpecFromStore(s, operators)
+		if err != nil {
+			return err
+		}
+		p.spec.Operators = append(p.spec.Operators, o)
+	}
+	return nil
+}
+
+func (p *ExportSpecParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ExportSpecParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ExportSpecParams) Run(ctx ActionCtx) error {
+	var d []byte
+	var err error
+	if p.json {
+		d, err = json.MarshalIndent(p.spec, "", "  ")
+		d = append(d, '\n')
+	} else {
+		d, err = yaml.Marshal(p.spec)
+	}
+	if err != nil {
+		return fmt.Errorf("error serializing spec: %v", err)
+	}
+	return Write(p.out, d)
+}
+
+// OperatorSpecFromStore describes the entities in the store, operators maps
+// the public keys of known operators to their names
+func OperatorSpecFromStore(s *store.Store, operators map[string]string) (*OperatorSpec, error) {
+	var err error
+	o := &OperatorSpec{Name: filepath.Base(s.Dir)}
+	if o.PublicKey, err = s.GetRootPublicKey(); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.ListSubContainers(store.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(accounts)
+	claims := make(map[string]*jwt.AccountClaims)
+	names := make(map[string]string)
+	for _, n := range accounts {
+		ac, err := s.ReadAccountClaim(n)
+		if err != nil {
+			return nil, err
+		}
+		claims[n] = ac
+		names[ac.Subject] = n
+	}
+
+	for _, n := range accounts {
+		ac := claims[n]
+		a := &AccountSpec{Name: n, PublicKey: ac.Subject, Tags: ac.Tags}
+		a.Limits.Conns = ac.Limits.Conn
+		for _, e := range ac.Exports {
+			es := &ExportSpec{Subject: string(e.Subject), Private: e.TokenReq}
+			if e.Name != string(e.Subject) {
+				es.Name = e.Name
+			}
+			if e.IsService() {
+				es.Type = jwt.Service.String()
+			}
+			a.Exports = append(a.Exports, es)
+		}
+		for _, im := range ac.Imports {
+			is := &ImportSpec{To: string(im.To)}
+			if im.Name != "" && im.Name != string(im.Subject) {
+				is.Name = im.Name
+			}
+			// activations for private exports in the store are generated by apply
+			if src, ok := names[im.Account]; ok && hasPrivateExport(claims[src], im.Subject) {
+				is.Account, is.Subject = src, string(im.Subject)
+			} else if im.Token != "" {
+				is.Token = im.Token
+			} else {
+				is.Account, is.Subject = im.Account, string(im.Subject)
+			}
+			a.Imports = append(a.Imports, is)
+		}
+
+		users, err := s.ListEntries(store.Accounts, n, store.Users)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(users)
+		for _, un := range users {
+			uc, err := s.ReadUserClaim(n, un)
+			if err != nil {
+				return nil, err
+			}
+			u := &UserSpec{Name: un, PublicKey: uc.Subject, Tags: uc.Tags,
+				AllowPub: uc.Pub.Allow, AllowSub: uc.Sub.Allow, DenyPub: uc.Pub.Deny, DenySub: uc.Sub.Deny}
+			if uc.Src != "" {
+				u.SourceNetworks = strings.Split(uc.Src, ",")
+			}
+			a.Users = append(a.Users, u)
+		}
+		o.Accounts = append(o.Accounts, a)
+	}
+
+	clusters, err := s.ListSubContainers(store.Clusters)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(clusters)
+	for _, n := range clusters {
+		cc, err := s.ReadClusterClaim(n)
+		if err != nil {
+			return nil, err
+		}
+		c := &ClusterSpec{Name: n, PublicKey: cc.Subject, Tags: cc.Tags,
+			AccountURLTemplate: cc.AccountURL, OperatorURLTemplate: cc.OperatorURL}
+		for _, pk := range cc.Accounts {
+			c.TrustedAccounts = append(c.TrustedAccounts, nameOrKey(names, pk))
+		}
+		for _, pk := range cc.Trust {
+			c.TrustedOperators = append(c.TrustedOperators, nameOrKey(operators, pk))
+		}
+
+		servers, err := s.ListEntries(store.Clusters, n, store.Servers)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(servers)
+		for _, sn := range servers {
+			sc, err := s.ReadServerClaim(n, sn)
+			if err != nil {
+				return nil, err
+			}
+			c.Servers = append(c.Servers, &ServerSpec{Name: sn, PublicKey: sc.Subject, Tags: sc.Tags})
+		}
+		o.Clusters = append(o.Clusters, c)
+	}
+	return o, nil
+}
+
+func hasPrivateExport(ac *jwt.AccountClaims, subject jwt.Subject) bool {
+	for _, e := range ac.Exports {
+		if e.TokenReq && subject.IsContainedIn(e.Subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func nameOrKey(names map[string]string, pk string) string {
+	if n, ok := names[pk]; ok {
+		return n
+	}
+	return pk
+}
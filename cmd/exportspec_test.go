@@ -0,0 +1,171 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExportSpec(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, applySpec))
+	require.NoError(t, err)
+
+	stdout, _, err := ExecuteCmd(createExportSpecCmd())
+	require.NoError(t, err)
+	spec, err := ParseSpec([]byte(stdout))
+	require.NoError(t, err)
+	require.Len(t, spec.Operators, 1)
+
+	o := spec.Operators[0]
+	require.Equal(t, "apply", o.Name)
+	require.Len(t, o.Accounts, 2)
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Equal(t, a.Subject, o.Accounts[0].PublicKey)
+	require.Equal(t, int64(10), o.Accounts[0].Limits.Conns)
+	require.Equal(t, "A", o.Accounts[1].Imports[0].Account)
+	require.Equal(t, []string{"192.168.1.0/24"}, o.Accounts[1].Users[0].SourceNetworks)
+	require.ElementsMatch(t, []string{"A", "B"}, o.Clusters[0].TrustedAccounts)
+
+	// no seeds
+	for _, f := range strings.Fields(stdout) {
+		_, err := nkeys.FromSeed([]byte(f))
+		require.Error(t, err, f)
+	}
+}
+
+func Test_ExportSpecJSON(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	fp := filepath.Join(ts.Dir, "spec.json")
+	_, stderr, err := ExecuteCmd(createExportSpecCmd(), "--json", "--output-file", fp)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Success! - wrote spec to")
+
+	d, err := ioutil.ReadFile(fp)
+	require.NoError(t, err)
+	var spec Spec
+	require.NoError(t, json.Unmarshal(d, &spec))
+	require.Equal(t, "A", spec.Operators[0].Accounts[0].Name)
+}
+
+func Test_ExportSpecReapplies(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(createApplyCmd(), "--file", writeSpec(t, ts, applySpec))
+	require.NoError(t, err)
+	fp := filepath.Join(ts.Dir, "exported.yaml")
+	_, _, err = ExecuteCmd(createExportSpecCmd(), "--output-file", fp)
+	require.NoError(t, err)
+
+	// applying the export to an empty stores directory using the same keys
+	// reproduces the entities
+	empty := filepath.Join(ts.Dir, "empty")
+	require.NoError(t, os.MkdirAll(empty, 0700))
+	require.NoError(t, ForceStoreRoot(t, empty))
+	_, stderr, err := ExecuteCmd(createApplyCmd(), "--file", fp)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Success! - applied")
+
+	s, err := store.LoadStore(filepath.Join(empty, "apply"))
+	require.NoError(t, err)
+	for _, n := range []string{"A", "B"} {
+		want, err := ts.Store.ReadAccountClaim(n)
+		require.NoError(t, err)
+		got, err := s.ReadAccountClaim(n)
+		require.NoError(t, err)
+		require.Equal(t, want.Subject, got.Subject)
+		require.Equal(t, want.Tags, got.Tags)
+		require.Equal(t, want.Limits, got.Limits)
+		require.ElementsMatch(t, want.Exports, got.Exports)
+		require.Len(t, got.Imports, len(want.Imports))
+	}
+	want, err := ts.Store.ReadUserClaim("B", "u")
+	require.NoError(t, err)
+	got, err := s.ReadUserClaim("B", "u")
+	require.NoError(t, err)
+	require.Equal(t, want.Subject, got.Subject)
+	require.Equal(t, want.Permissions, got.Permissions)
+	require.Equal(t, want.Src, got.Src)
+
+	wc, err := ts.Store.ReadClusterClaim("C")
+	require.NoError(t, err)
+	gc, err := s.ReadClusterClaim("C")
+	require.NoError(t, err)
+	require.Equal(t, wc.Subject, gc.Subject)
+	require.ElementsMatch(t, wc.Accounts, gc.Accounts)
+
+	_, stderr, err = ExecuteCmd(createApplyCmd(), "--file", fp)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No changes - the store matches the spec")
+}
+
+func Test_ExportSpecReappliesExternalImport(t *testing.T) {
+	ts := NewTestStore(t, "apply")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "B")
+	bpk, err := ts.KeyStore.GetAccountPublicKey("B")
+	require.NoError(t, err)
+	_, xpk, xkp := CreateAccountKey(t)
+	act := jwt.NewActivationClaims(bpk)
+	act.ImportSubject = "foo.>"
+	act.ImportType = jwt.Stream
+	token, err := act.Encode(xkp)
+	require.NoError(t, err)
+	tf := filepath.Join(ts.Dir, "external.jwt")
+	require.NoError(t, ioutil.WriteFile(tf, []byte(token), 0600))
+	_, _, err = ExecuteCmd(createAddImportCmd(), "--account", "B", "--token", tf)
+	require.NoError(t, err)
+
+	fp := filepath.Join(ts.Dir, "exported.yaml")
+	_, _, err = ExecuteCmd(createExportSpecCmd(), "--output-file", fp)
+	require.NoError(t, err)
+
+	// the inline token matches the import in the store
+	_, stderr, err := ExecuteCmd(createApplyCmd(), "--file", fp)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No changes - the store matches the spec")
+
+	empty := filepath.Join(ts.Dir, "empty")
+	require.NoError(t, os.MkdirAll(empty, 0700))
+	require.NoError(t, ForceStoreRoot(t, empty))
+	_, _, err = ExecuteCmd(createApplyCmd(), "--file", fp)
+	require.NoError(t, err)
+	s, err := store.LoadStore(filepath.Join(empty, "apply"))
+	require.NoError(t, err)
+	ac, err := s.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Len(t, ac.Imports, 1)
+	require.Equal(t, xpk, ac.Imports[0].Account)
+	require.Equal(t, token, ac.Imports[0].Token)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/nats-io/jwt"
 	"github.com/nats-io/nkeys"
 	"github.com/nats-io/nsc/cli"
+	"github.com/nats-io/nsc/cmd/store"
 	"github.com/spf13/cobra"
 )
 
@@ -216,5 +217,9 @@ func (p *GenerateActivationParams) Run(ctx ActionCtx) error {
 		return err
 	}
 
-	return nil
+	r, err := store.NewActivationRecord(p.Token)
+	if err != nil {
+		return err
+	}
+	return ctx.StoreCtx().Store.StoreActivation(p.AccountContextParams.Name, r)
 }
@@ -116,6 +116,10 @@ func (p *GenerateConfigParams) Validate(ctx ActionCtx) error {
 		return err
 	}
 
+	if p.name, err = ctx.StoreCtx().Store.ResolveUser(p.AccountContextParams.Name, p.name); err != nil {
+		return err
+	}
+
 	if !ctx.StoreCtx().Store.Has(store.Accounts, p.AccountContextParams.Name, store.Users, store.JwtName(p.name)) {
 		return fmt.Errorf("user %q not found", p.name)
 	}
@@ -86,7 +86,7 @@ init --interactive
 	cmd.Flags().StringVarP(&p.environmentName, "name", "n", "test", "name for the configuration environment")
 
 	cmd.Flags().StringVarP(&p.operator.name, "operator-name", "", "", "operator name (default '<name>_operator')")
-	cmd.Flags().StringVarP(&p.operator.keyPath, "operator-key", "", "", "operator keypath (default generated)")
+	cmd.Flags().StringVarP(&p.operator.keyPath, "operator-key", "", "", "operator keypath (default generated), or the public key of the external operator without --create-operator")
 	cmd.Flags().BoolVarP(&p.operator.create, "create-operator", "", true, "create an operator")
 
 	cmd.Flags().StringVarP(&p.account.name, "account-name", "", "", "name for the account (default '<name>_account')")
@@ -304,10 +304,20 @@ func (p *InitParams) Run() error {
 		operator = &store.NamedKey{Name: p.environmentName}
 	}
 
-	_, err := store.CreateStore(p.environmentName, p.storeRoot, operator)
+	s, err := store.CreateStore(p.environmentName, p.storeRoot, operator)
 	if err != nil {
 		return err
 	}
+	if !p.operator.create && p.operator.keyPath != "" {
+		// the operator is external - record its public key to accept its accounts
+		pub, err := p.operator.kp.PublicKey()
+		if err != nil {
+			return err
+		}
+		if err := s.SetOperatorKey(pub); err != nil {
+			return err
+		}
+	}
 
 	GetConfig().Operator = operator.Name
 	if err := GetConfig().Save(); err != nil {
@@ -332,13 +342,34 @@ func (p *InitParams) Run() error {
 		}
 	}
 
-	for i, c := range containers {
-		// operator container is created by the store - some flags may prevent creation
-		if !c.create || i == 0 {
+	for _, c := range containers {
+		// the operator JWT is created by the store - some flags may prevent creation
+		if !c.create || c.kind == nkeys.PrefixByteOperator {
 			continue
 		}
-		c.GenerateClaim(containers[i-1].kp, nil)
+		if err := c.GenerateClaim(p.signer(c.kind), nil); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// signer returns the key that signs the JWT of the kind - the operator signs accounts
+// and clusters, the account signs users and the cluster signs servers. If the signer
+// is not created, as an external operator, it returns nil and the JWT is self-signed.
+func (p *InitParams) signer(kind nkeys.PrefixByte) nkeys.KeyPair {
+	var parent *Entity
+	switch kind {
+	case nkeys.PrefixByteAccount, nkeys.PrefixByteCluster:
+		parent = &p.operator
+	case nkeys.PrefixByteUser:
+		parent = &p.account
+	case nkeys.PrefixByteServer:
+		parent = &p.cluster
+	}
+	if parent == nil || !parent.create {
+		return nil
+	}
+	return parent.kp
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+// requireSigners checks the issuers of the JWTs created by init
+func requireSigners(t *testing.T, s *store.Store, operator string, name string) {
+	ac, err := s.ReadAccountClaim(name + "_account")
+	require.NoError(t, err)
+	require.Equal(t, operator, ac.Issuer)
+	uc, err := s.ReadUserClaim(name+"_account", name+"_user")
+	require.NoError(t, err)
+	require.Equal(t, ac.Subject, uc.Issuer)
+	cc, err := s.ReadClusterClaim(name + "_cluster")
+	require.NoError(t, err)
+	if operator == ac.Subject {
+		// self-signed without the operator
+		require.Equal(t, cc.Subject, cc.Issuer)
+	} else {
+		require.Equal(t, operator, cc.Issuer)
+	}
+	sc, err := s.ReadServerClaim(name+"_cluster", "localhost")
+	require.NoError(t, err)
+	require.Equal(t, cc.Subject, sc.Issuer)
+}
+
+func Test_InitSigners(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(CreateInitCmd(), "--name", "p", "--create-cluster", "--create-server")
+	require.NoError(t, err)
+	s, err := store.LoadStore(filepath.Join(ts.GetStoresRoot(), "p_operator"))
+	require.NoError(t, err)
+	opk, err := s.GetRootPublicKey()
+	require.NoError(t, err)
+	requireSigners(t, s, opk, "p")
+}
+
+func Test_InitExternalOperator(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	_, opub, _ := CreateOperatorKey(t)
+	_, _, err := ExecuteCmd(CreateInitCmd(), "--name", "ext", "--create-operator=false", "--operator-key", opub,
+		"--create-cluster", "--create-server")
+	require.NoError(t, err)
+
+	s, err := store.LoadStore(filepath.Join(ts.GetStoresRoot(), "ext"))
+	require.NoError(t, err)
+	require.True(t, s.IsManaged())
+	require.Equal(t, opub, s.Info.OperatorKey)
+
+	// the accounts are self-signed until the operator signs them
+	ac, err := s.ReadAccountClaim("ext_account")
+	require.NoError(t, err)
+	requireSigners(t, s, ac.Subject, "ext")
+}
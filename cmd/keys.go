@@ -0,0 +1,263 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+// keysCmd represents the keys command
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the seeds in the keystore",
+}
+
+func init() {
+	GetRootCmd().AddCommand(keysCmd)
+}
+
+const (
+	// KeyOk is a seed for a JWT in the store
+	KeyOk = "ok"
+	// KeyOrphan is a seed that doesn't match a JWT in the store
+	KeyOrphan = "orphan"
+	// KeyNoSeed is a JWT in the store without a seed in the keystore
+	KeyNoSeed = "no seed"
+	// KeyMismatch is a seed at the path of an entity with a different public key
+	KeyMismatch = "mismatch"
+	// KeyInvalid is a key file that can't be read as a seed
+	KeyInvalid = "invalid"
+)
+
+// KeyEntry describes a seed in the keystore or a JWT without a seed
+type KeyEntry struct {
+	Kind      string
+	Name      string
+	PublicKey string
+	Path      string
+	Status    string
+	Detail    string
+}
+
+type keyEntity struct {
+	kind    nkeys.PrefixByte
+	parent  string
+	name    string
+	subject string
+	path    string
+	seeded  bool
+}
+
+// KeyInventory matches the seeds in the keystore to the JWTs in the store by public key
+func KeyInventory(sctx *store.Context) ([]*KeyEntry, error) {
+	entities, err := storeEntities(sctx)
+	if err != nil {
+		return nil, err
+	}
+	bySubject := make(map[string]*keyEntity)
+	byPath := make(map[string]*keyEntity)
+	for _, e := range entities {
+		bySubject[e.subject] = e
+		byPath[e.path] = e
+	}
+
+	paths, err := sctx.KeyStore.ListKeys()
+	if err != nil {
+		return nil, err
+	}
+	var entries []*KeyEntry
+	for _, fp := range paths {
+		ke := &KeyEntry{Path: fp, Name: keyFileName(sctx.KeyStore.Dir(), fp)}
+		entries = append(entries, ke)
+
+		kp, err := sctx.KeyStore.Read(fp)
+		if err == nil {
+			_, err = kp.Seed()
+		}
+		if err != nil {
+			ke.Status = KeyInvalid
+			ke.Detail = "not a seed"
+			continue
+		}
+		ke.PublicKey, _ = kp.PublicKey()
+		if kt, err := store.KeyType(kp); err == nil {
+			ke.Kind = kt.String()
+		}
+
+		if e, ok := bySubject[ke.PublicKey]; ok {
+			e.seeded = true
+			ke.Name = e.displayName()
+			ke.Status = KeyOk
+			if e.path != fp {
+				ke.Detail = fmt.Sprintf("expected at %s", e.path)
+			}
+			continue
+		}
+		if e, ok := byPath[fp]; ok {
+			ke.Name = e.displayName()
+			ke.Status = KeyMismatch
+			ke.Detail = fmt.Sprintf("JWT subject is %s", e.subject)
+			continue
+		}
+		ke.Status = KeyOrphan
+	}
+
+	for _, e := range entities {
+		if !e.seeded {
+			entries = append(entries, &KeyEntry{Kind: e.kind.String(), Name: e.displayName(), PublicKey: e.subject, Path: e.path, Status: KeyNoSeed})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+// keyFileName returns the name of the entity of a key from its path, users and
+// servers are prefixed by the name of their parent
+func keyFileName(dir string, fp string) string {
+	name := strings.TrimSuffix(filepath.Base(fp), "."+store.NKeyExtension)
+	rel, err := filepath.Rel(dir, fp)
+	if err != nil {
+		return name
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) == 4 {
+		return filepath.Join(parts[1], name)
+	}
+	return name
+}
+
+// displayName returns the name of the entity, users and servers are prefixed
+// by the name of their parent
+func (e *keyEntity) displayName() string {
+	if e.parent != "" {
+		return filepath.Join(e.parent, e.name)
+	}
+	return e.name
+}
+
+// storeEntities lists the entities with a JWT in the store
+func storeEntities(sctx *store.Context) ([]*keyEntity, error) {
+	s := sctx.Store
+	ks := sctx.KeyStore
+	var entities []*keyEntity
+	add := func(kind nkeys.PrefixByte, parent string, name string, c *jwt.GenericClaims) {
+		if c == nil {
+			return
+		}
+		entities = append(entities, &keyEntity{kind: kind, parent: parent, name: name, subject: c.Subject, path: ks.KeyPath(kind, parent, name)})
+	}
+
+	oc, err := s.LoadRootClaim()
+	if err != nil {
+		return nil, err
+	}
+	if oc != nil {
+		add(nkeys.PrefixByteOperator, "", oc.Name, oc)
+	}
+
+	containers := []struct {
+		dir      string
+		entries  string
+		kind     nkeys.PrefixByte
+		children nkeys.PrefixByte
+	}{
+		{store.Accounts, store.Users, nkeys.PrefixByteAccount, nkeys.PrefixByteUser},
+		{store.Clusters, store.Servers, nkeys.PrefixByteCluster, nkeys.PrefixByteServer},
+	}
+	for _, ct := range containers {
+		names, err := s.ListSubContainers(ct.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range names {
+			c, err := s.LoadClaim(ct.dir, n, store.JwtName(n))
+			if err != nil {
+				return nil, err
+			}
+			add(ct.kind, "", n, c)
+
+			children, err := s.ListEntries(ct.dir, n, ct.entries)
+			if err != nil {
+				return nil, err
+			}
+			for _, cn := range children {
+				c, err := s.LoadClaim(ct.dir, n, ct.entries, store.JwtName(cn))
+				if err != nil {
+					return nil, err
+				}
+				add(ct.children, n, cn, c)
+			}
+		}
+	}
+	return entities, nil
+}
+
+// keysTable renders the entries with their path relative to the keystore
+func keysTable(title string, dir string, entries []*KeyEntry) string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle(title)
+	table.AddHeaders("Kind", "Name", "Public Key", "Seed", "Status")
+	for _, e := range entries {
+		fp := e.Path
+		if rel, err := filepath.Rel(dir, fp); err == nil {
+			fp = rel
+		}
+		if e.Status == KeyNoSeed {
+			fp = ""
+		}
+		status := e.Status
+		if e.Detail != "" {
+			status = fmt.Sprintf("%s - %s", status, e.Detail)
+		}
+		table.AddRow(e.Kind, e.Name, e.PublicKey, fp, status)
+	}
+	return table.Render()
+}
+
+// moveKey moves a key file under dir to the same relative path under backup, and
+// removes the directories left empty up to dir
+func moveKey(dir string, backup string, fp string) error {
+	rel, err := filepath.Rel(dir, fp)
+	if err != nil {
+		return err
+	}
+	dst := filepath.Join(backup, rel)
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(fp, dst); err != nil {
+		return err
+	}
+	for d := filepath.Dir(fp); d != dir && len(d) > len(dir); d = filepath.Dir(d) {
+		if os.Remove(d) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cli"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// BackupType is the PEM type of an encrypted keys backup
+	BackupType = "NSC KEYS BACKUP"
+	// BackupShareType is the PEM type of a share of an encrypted keys backup
+	BackupShareType = "NSC KEYS BACKUP SHARE"
+
+	backupVersion   = 1
+	backupSaltLen   = 16
+	backupNonceLen  = 24
+	minPassphrase   = 8
+	scryptN         = 1 << 15
+	scryptR         = 8
+	scryptP         = 1
+	secretboxKeyLen = 32
+)
+
+func createKeysBackupCmd() *cobra.Command {
+	var params KeysBackupParams
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Export seeds into a passphrase encrypted backup",
+		Long: `Export seeds into a passphrase encrypted backup
+
+The backup is a PEM block that can be printed, or encoded in a QR code. By
+default only the operator seed is exported, --all exports the seeds of every
+JWT in the store.
+
+With --shares the backup is split into shares with Shamir's secret sharing,
+and any --threshold of them, together with the passphrase, restore the seeds.
+Output files for shares are suffixed with the number of the share.`,
+		Example: `nsc keys backup --output-file operator.backup
+nsc keys backup --all --passphrase-file pass.txt --output-file all.backup
+nsc keys backup --shares 5 --threshold 3 --output-file operator.share`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			if params.shares > 0 {
+				cmd.Printf("Success! - backed up %d seed(s) in %d shares, %d are required to restore\n", len(params.backup.Keys), params.shares, params.threshold)
+			} else {
+				cmd.Printf("Success! - backed up %d seed(s)\n", len(params.backup.Keys))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&params.all, "all", "", false, "export the seeds of all the entities in the store")
+	cmd.Flags().StringVarP(&params.out, "output-file", "o", "--", "output file, '--' is stdout")
+	cmd.Flags().StringVarP(&params.passphraseFile, "passphrase-file", "", "", "file with the passphrase, prompted if not specified")
+	markSecret(cmd, "passphrase-file")
+	cmd.Flags().IntVarP(&params.shares, "shares", "", 0, "split the backup into shares")
+	cmd.Flags().IntVarP(&params.threshold, "threshold", "", 0, "number of shares required to restore")
+
+	return cmd
+}
+
+func init() {
+	keysCmd.AddCommand(createKeysBackupCmd())
+}
+
+// KeysBackup are the seeds in a backup
+type KeysBackup struct {
+	Operator string      `json:"operator"`
+	Created  int64       `json:"created"`
+	Keys     []BackupKey `json:"keys"`
+}
+
+// BackupKey is a seed of an entity, users and servers have a parent
+type BackupKey struct {
+	Kind   string `json:"kind"`
+	Parent string `json:"parent,omitempty"`
+	Name   string `json:"name"`
+	Seed   string `json:"seed"`
+}
+
+type KeysBackupParams struct {
+	all            bool
+	out            string
+	passphraseFile string
+	passphrase     string
+	shares         int
+	threshold      int
+	backup         KeysBackup
+}
+
+func (p *KeysBackupParams) SetDefaults(ctx ActionCtx) error {
+	if p.shares == 0 && p.threshold > 0 {
+		ctx.CurrentCmd().SilenceUsage = false
+		return errors.New("--threshold requires --shares")
+	}
+	if p.shares > 0 && (p.threshold < 2 || p.threshold > p.shares) {
+		ctx.CurrentCmd().SilenceUsage = false
+		return errors.New("--threshold must be between 2 and --shares")
+	}
+	return nil
+}
+
+func (p *KeysBackupParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysBackupParams) Load(ctx ActionCtx) error {
+	sctx := ctx.StoreCtx()
+	entities, err := storeEntities(sctx)
+	if err != nil {
+		return err
+	}
+	p.backup.Operator = sctx.Operator.Name
+	for _, e := range entities {
+		if !p.all && e.kind != nkeys.PrefixByteOperator {
+			continue
+		}
+		kp, err := sctx.KeyStore.Read(e.path)
+		if err != nil {
+			return fmt.Errorf("error reading the seed of %s %q: %v", e.kind.String(), e.displayName(), err)
+		}
+		if kp == nil {
+			continue
+		}
+		seed, err := kp.Seed()
+		if err != nil {
+			continue
+		}
+		if pk, _ := kp.PublicKey(); pk != e.subject {
+			return fmt.Errorf("the seed of %s %q doesn't match its JWT", e.kind.String(), e.displayName())
+		}
+		p.backup.Keys = append(p.backup.Keys, BackupKey{Kind: e.kind.String(), Parent: e.parent, Name: e.name, Seed: string(seed)})
+	}
+	if len(p.backup.Keys) == 0 {
+		if p.all {
+			return errors.New("no seeds to back up")
+		}
+		return errors.New("the operator seed is not in the keystore")
+	}
+	return nil
+}
+
+func (p *KeysBackupParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysBackupParams) Validate(ctx ActionCtx) error {
+	var err error
+	p.passphrase, err = readPassphrase(p.passphraseFile, true)
+	if err != nil {
+		return err
+	}
+	if len(p.passphrase) < minPassphrase {
+		return fmt.Errorf("the passphrase must have at least %d characters", minPassphrase)
+	}
+	return nil
+}
+
+func (p *KeysBackupParams) Run(ctx ActionCtx) error {
+	p.backup.Created = time.Now().Unix()
+	data, err := EncryptBackup(&p.backup, p.passphrase)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{"Operator": p.backup.Operator, "Created": UnixToDate(p.backup.Created)}
+	if p.shares == 0 {
+		return WriteSecret(p.out, pem.EncodeToMemory(&pem.Block{Type: BackupType, Headers: headers, Bytes: data}))
+	}
+
+	shares, err := SplitSecret(data, p.shares, p.threshold)
+	if err != nil {
+		return err
+	}
+	var all bytes.Buffer
+	for _, s := range shares {
+		headers["Share"] = strconv.Itoa(int(s.X))
+		headers["Shares"] = strconv.Itoa(p.shares)
+		headers["Threshold"] = strconv.Itoa(int(s.Threshold))
+		block := pem.EncodeToMemory(&pem.Block{Type: BackupShareType, Headers: headers, Bytes: s.Data})
+		if IsStdOut(p.out) {
+			all.Write(block)
+			continue
+		}
+		if err := WriteSecret(fmt.Sprintf("%s.%d", p.out, s.X), block); err != nil {
+			return err
+		}
+	}
+	if IsStdOut(p.out) {
+		return Write(p.out, all.Bytes())
+	}
+	return nil
+}
+
+// readPassphrase reads the passphrase from a file, or prompts for it
+func readPassphrase(file string, confirm bool) (string, error) {
+	if file != "" {
+		d, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("error reading the passphrase: %v", err)
+		}
+		return strings.TrimRight(string(d), "\r\n"), nil
+	}
+	v, err := cli.PromptSecret("passphrase")
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		c, err := cli.PromptSecret("confirm passphrase")
+		if err != nil {
+			return "", err
+		}
+		if c != v {
+			return "", errors.New("the passphrases don't match")
+		}
+	}
+	return v, nil
+}
+
+func backupKey(passphrase string, salt []byte) (*[secretboxKeyLen]byte, error) {
+	k, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, secretboxKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	var key [secretboxKeyLen]byte
+	copy(key[:], k)
+	return &key, nil
+}
+
+// EncryptBackup serializes and encrypts a backup with a key derived from the
+// passphrase. The result is the version, the salt, the nonce and the sealed box.
+func EncryptBackup(b *KeysBackup, passphrase string) ([]byte, error) {
+	d, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, backupSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	var nonce [backupNonceLen]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key, err := backupKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte{backupVersion}, salt...)
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, d, &nonce, key), nil
+}
+
+// DecryptBackup decrypts a backup encrypted by EncryptBackup
+func DecryptBackup(data []byte, passphrase string) (*KeysBackup, error) {
+	if len(data) < 1+backupSaltLen+backupNonceLen+secretbox.Overhead {
+		return nil, errors.New("the backup is truncated")
+	}
+	if data[0] != backupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", data[0])
+	}
+	salt := data[1 : 1+backupSaltLen]
+	var nonce [backupNonceLen]byte
+	copy(nonce[:], data[1+backupSaltLen:1+backupSaltLen+backupNonceLen])
+	key, err := backupKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := secretbox.Open(nil, data[1+backupSaltLen+backupNonceLen:], &nonce, key)
+	if !ok {
+		return nil, errors.New("unable to decrypt the backup - wrong passphrase or corrupted backup")
+	}
+	var b KeysBackup
+	if err := json.Unmarshal(d, &b); err != nil {
+		return nil, fmt.Errorf("error parsing the backup: %v", err)
+	}
+	return &b, nil
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func writePassphrase(t *testing.T, dir string, v string) string {
+	fp := filepath.Join(dir, "passphrase.txt")
+	require.NoError(t, ioutil.WriteFile(fp, []byte(v+"\n"), 0600))
+	return fp
+}
+
+func Test_KeysBackupRestoreOperator(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	dir := MakeTempDir(t)
+	pass := writePassphrase(t, dir, "correct horse battery")
+	backup := filepath.Join(dir, "operator.backup")
+	_, stderr, err := ExecuteCmd(createKeysBackupCmd(), "--passphrase-file", pass, "--output-file", backup)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "backed up 1 seed(s)")
+
+	fi, err := os.Stat(backup)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	d, err := ioutil.ReadFile(backup)
+	require.NoError(t, err)
+	require.Contains(t, string(d), "BEGIN "+BackupType)
+	seed, err := ts.OperatorKey.Seed()
+	require.NoError(t, err)
+	require.NotContains(t, string(d), string(seed))
+
+	opk := ts.KeyStore.KeyPath(nkeys.PrefixByteOperator, "", "O")
+	require.NoError(t, os.Remove(opk))
+
+	_, _, err = ExecuteCmd(createKeysRestoreCmd(), "--passphrase-file", writePassphrase(t, MakeTempDir(t), "wrong passphrase"), backup)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "wrong passphrase")
+	_, err = os.Stat(opk)
+	require.True(t, os.IsNotExist(err))
+
+	_, stderr, err = ExecuteCmd(createKeysRestoreCmd(), "--passphrase-file", pass, backup)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "restored 1 seed(s)")
+	kp, err := ts.KeyStore.GetOperatorKey("O")
+	require.NoError(t, err)
+	require.NotNil(t, kp)
+	rs, err := kp.Seed()
+	require.NoError(t, err)
+	require.Equal(t, seed, rs)
+}
+
+func Test_KeysBackupShares(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+	ts.AddUser(t, "A", "a")
+	ts.AddServer(t, "C", "s")
+
+	dir := MakeTempDir(t)
+	pass := writePassphrase(t, dir, "correct horse battery")
+	out := filepath.Join(dir, "all.share")
+	_, stderr, err := ExecuteCmd(createKeysBackupCmd(), "--all", "--passphrase-file", pass, "--shares", "5", "--threshold", "3", "--output-file", out)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "backed up 5 seed(s) in 5 shares, 3 are required")
+
+	ukp := ts.KeyStore.KeyPath(nkeys.PrefixByteUser, "A", "a")
+	require.NoError(t, os.Remove(ukp))
+
+	_, _, err = ExecuteCmd(createKeysRestoreCmd(), "--passphrase-file", pass, out+".1", out+".4")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "3 shares are required")
+
+	_, stderr, err = ExecuteCmd(createKeysRestoreCmd(), "--passphrase-file", pass, out+".2", out+".5", out+".3")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "restored 1 seed(s)")
+	require.FileExists(t, ukp)
+}
+
+func Test_KeysRestoreChecksTheStore(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	dir := MakeTempDir(t)
+	pass := writePassphrase(t, dir, "correct horse battery")
+	backup := filepath.Join(dir, "all.backup")
+	_, _, err := ExecuteCmd(createKeysBackupCmd(), "--all", "--passphrase-file", pass, "--output-file", backup)
+	require.NoError(t, err)
+
+	// the account is replaced by another one with the same name
+	akp := ts.KeyStore.KeyPath(nkeys.PrefixByteAccount, "", "A")
+	require.NoError(t, os.RemoveAll(filepath.Dir(akp)))
+	require.NoError(t, os.RemoveAll(filepath.Join(ts.Store.Dir, "accounts", "A")))
+	ts.AddAccount(t, "A")
+	require.NoError(t, os.Remove(akp))
+
+	_, stderr, err := ExecuteCmd(createKeysRestoreCmd(), "--passphrase-file", pass, backup)
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "skipped - JWT has another public key")
+	require.Contains(t, stderr, "restored 0 seed(s)")
+	_, err = os.Stat(akp)
+	require.True(t, os.IsNotExist(err))
+}
+
+func Test_KeysBackupFlags(t *testing.T) {
+	ts := NewTestStore(t, "O")
+	defer ts.Done(t)
+
+	pass := writePassphrase(t, MakeTempDir(t), "short")
+	tests := CmdTests{
+		{createKeysBackupCmd(), []string{"keys", "backup", "--threshold", "2"}, nil, []string{"--threshold requires --shares"}, true},
+		{createKeysBackupCmd(), []string{"keys", "backup", "--shares", "3", "--threshold", "4"}, nil, []string{"--threshold must be between 2 and --shares"}, true},
+		{createKeysBackupCmd(), []string{"keys", "backup", "--passphrase-file", pass}, nil, []string{"at least 8 characters"}, true},
+	}
+	tests.Run(t, "root", "keys")
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func createKeysListCmd() *cobra.Command {
+	var params KeysListParams
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the seeds in the keystore and the JWTs they belong to",
+		Long: `List the seeds in the keystore and the JWTs they belong to
+
+Seeds are matched to the JWTs in the store by public key. Orphan seeds don't
+match any JWT, mismatched seeds are at the path of an entity but have another
+public key, and JWTs without a seed can't be edited without a key.`,
+		Example:      "nsc keys list",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			counts := make(map[string]int)
+			for _, e := range params.entries {
+				counts[e.Status]++
+			}
+			cmd.Printf("%d orphan seed(s), %d JWT(s) without seeds, %d mismatch(es)\n", counts[KeyOrphan], counts[KeyNoSeed], counts[KeyMismatch])
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.out, "output-file", "o", "--", "output file, '--' is stdout")
+
+	return cmd
+}
+
+func init() {
+	keysCmd.AddCommand(createKeysListCmd())
+}
+
+type KeysListParams struct {
+	out     string
+	entries []*KeyEntry
+}
+
+func (p *KeysListParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysListParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysListParams) Load(ctx ActionCtx) error {
+	var err error
+	p.entries, err = KeyInventory(ctx.StoreCtx())
+	return err
+}
+
+func (p *KeysListParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysListParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysListParams) Run(ctx ActionCtx) error {
+	ks := ctx.StoreCtx().KeyStore
+	return Write(p.out, []byte(keysTable("Keys", ks.Dir(), p.entries)))
+}
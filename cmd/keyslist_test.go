@@ -0,0 +1,68 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KeysList(t *testing.T) {
+	ts := NewTestStore(t, "keys list")
+	defer ts.Done(t)
+
+	ts.AddUser(t, "A", "a")
+	ts.AddUser(t, "A", "b")
+	ts.AddServer(t, "C", "s")
+
+	// a seed left behind by a failed add
+	_, opk, okp := CreateAccountKey(t)
+	_, err := ts.KeyStore.Store("X", okp, "")
+	require.NoError(t, err)
+
+	// a user without a seed
+	require.NoError(t, os.Remove(ts.KeyStore.KeyPath(nkeys.PrefixByteUser, "A", "b")))
+
+	// a seed replaced by another key
+	seed, mpk, _ := CreateClusterKey(t)
+	require.NoError(t, ioutil.WriteFile(ts.KeyStore.KeyPath(nkeys.PrefixByteCluster, "", "C"), seed, 0600))
+
+	sctx, err := ts.Store.GetContext()
+	require.NoError(t, err)
+	entries, err := KeyInventory(sctx)
+	require.NoError(t, err)
+	status := make(map[string]string)
+	for _, e := range entries {
+		status[e.Name+"/"+e.Status] = e.PublicKey
+	}
+	require.Contains(t, status, "keys list/"+KeyOk)
+	require.Contains(t, status, "A/"+KeyOk)
+	require.Contains(t, status, "A/a/"+KeyOk)
+	require.Contains(t, status, "C/s/"+KeyOk)
+	require.Equal(t, opk, status["X/"+KeyOrphan])
+	require.Contains(t, status, "A/b/"+KeyNoSeed)
+	require.Equal(t, mpk, status["C/"+KeyMismatch])
+	require.Contains(t, status, "C/"+KeyNoSeed)
+
+	out, stderr, err := ExecuteCmd(createKeysListCmd())
+	require.NoError(t, err)
+	require.Contains(t, out, opk)
+	require.Contains(t, stderr, "1 orphan seed(s), 2 JWT(s) without seeds, 1 mismatch(es)")
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/nats-io/nsc/cli"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+)
+
+func createKeysPruneCmd() *cobra.Command {
+	var params KeysPruneParams
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the orphan seeds from the keystore",
+		Long: `Remove the orphan seeds from the keystore
+
+Only seeds whose public key doesn't match any JWT in the store are removed.
+Mismatched seeds and files that are not seeds are left for review with
+'nsc keys list'.
+
+The keystore is shared by the stores of operators with the same name, so
+removing requires --force or a confirmation with --interactive, and the
+removed seeds are moved to a backup directory in the keys directory.`,
+		Example: `nsc keys prune --dry-run
+nsc keys prune -i
+nsc keys prune --force`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			switch {
+			case len(params.orphans) == 0:
+				cmd.Println("No orphan seeds in the keystore")
+			case params.dryRun:
+				cmd.Println(params.table)
+				cmd.Printf("Dry run - %d orphan seed(s) not removed\n", len(params.orphans))
+			default:
+				cmd.Println(params.table)
+				cmd.Printf("Success! - moved %d orphan seed(s) to %q\n", len(params.orphans), params.backup)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&params.dryRun, "dry-run", "", false, "only list the orphan seeds")
+	cmd.Flags().BoolVarP(&params.force, "force", "F", false, "remove the orphan seeds without confirmation")
+
+	return cmd
+}
+
+func init() {
+	keysCmd.AddCommand(createKeysPruneCmd())
+}
+
+type KeysPruneParams struct {
+	dryRun    bool
+	force     bool
+	confirmed bool
+	orphans   []*KeyEntry
+	backup    string
+	table     string
+}
+
+func (p *KeysPruneParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysPruneParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysPruneParams) Load(ctx ActionCtx) error {
+	entries, err := KeyInventory(ctx.StoreCtx())
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Status == KeyOrphan {
+			p.orphans = append(p.orphans, e)
+		}
+	}
+	return nil
+}
+
+func (p *KeysPruneParams) PostInteractive(ctx ActionCtx) error {
+	if len(p.orphans) == 0 || p.dryRun || p.force {
+		return nil
+	}
+	ks := ctx.StoreCtx().KeyStore
+	ctx.CurrentCmd().Println(keysTable("Orphan Seeds", ks.Dir(), p.orphans))
+	ok, err := cli.PromptBoolean(fmt.Sprintf("move %d orphan seed(s) to %q", len(p.orphans), ks.PrunedDir()), false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("cancelled")
+	}
+	p.confirmed = true
+	return nil
+}
+
+func (p *KeysPruneParams) Validate(ctx ActionCtx) error {
+	if len(p.orphans) > 0 && !p.dryRun && !p.force && !p.confirmed {
+		ctx.CurrentCmd().SilenceUsage = false
+		return fmt.Errorf("%d orphan seed(s) would be removed - specify --force or confirm with --interactive", len(p.orphans))
+	}
+	return nil
+}
+
+func (p *KeysPruneParams) Run(ctx ActionCtx) error {
+	ks := ctx.StoreCtx().KeyStore
+	dir := ks.Dir()
+	title := "Removed Seeds"
+	if p.dryRun {
+		title = "Orphan Seeds (dry-run)"
+	} else {
+		s := ctx.StoreCtx().Store
+		p.backup = filepath.Join(ks.PrunedDir(), time.Now().UTC().Format("20060102T150405Z"))
+		for _, e := range p.orphans {
+			if err := moveKey(dir, p.backup, e.Path); err != nil {
+				return fmt.Errorf("error removing %q: %v", e.Path, err)
+			}
+			s.RecordChange(store.ClaimChange{Kind: SeedRemoved, Name: e.Name, PublicKey: e.PublicKey})
+		}
+	}
+	p.table = keysTable(title, dir, p.orphans)
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KeysPrune(t *testing.T) {
+	ts := NewTestStore(t, "keys prune")
+	defer ts.Done(t)
+
+	ts.AddUser(t, "A", "a")
+	_, _, akp := CreateAccountKey(t)
+	orphan, err := ts.KeyStore.Store("X", akp, "")
+	require.NoError(t, err)
+	_, _, ukp := CreateUserKey(t)
+	userOrphan, err := ts.KeyStore.Store("old", ukp, "A")
+	require.NoError(t, err)
+
+	_, stderr, err := ExecuteCmd(createKeysPruneCmd(), "--dry-run")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Dry run - 2 orphan seed(s) not removed")
+	require.FileExists(t, orphan)
+	require.FileExists(t, userOrphan)
+
+	_, _, err = ExecuteCmd(createKeysPruneCmd())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "2 orphan seed(s) would be removed")
+	require.FileExists(t, orphan)
+
+	_, stderr, err = ExecuteCmd(createKeysPruneCmd(), "--force")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "moved 2 orphan seed(s)")
+
+	_, err = os.Stat(orphan)
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Dir(orphan))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(userOrphan)
+	require.True(t, os.IsNotExist(err))
+	require.FileExists(t, ts.KeyStore.KeyPath(nkeys.PrefixByteUser, "A", "a"))
+	require.FileExists(t, ts.KeyStore.KeyPath(nkeys.PrefixByteAccount, "", "A"))
+
+	_, stderr, err = ExecuteCmd(createKeysPruneCmd())
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No orphan seeds")
+}
+
+func Test_KeysPruneInteractive(t *testing.T) {
+	ts := NewTestStore(t, "keys prune")
+	defer ts.Done(t)
+
+	_, apub, akp := CreateAccountKey(t)
+	orphan, err := ts.KeyStore.Store("X", akp, "")
+	require.NoError(t, err)
+
+	cmd := createKeysPruneCmd()
+	HoistRootFlags(cmd)
+	_, _, err = ExecuteInteractiveCmd(cmd, []interface{}{false}, "-i")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cancelled")
+	require.FileExists(t, orphan)
+
+	cmd = createKeysPruneCmd()
+	HoistRootFlags(cmd)
+	_, _, err = ExecuteInteractiveCmd(cmd, []interface{}{true}, "-i")
+	require.NoError(t, err)
+	_, err = os.Stat(orphan)
+	require.True(t, os.IsNotExist(err))
+
+	// the seed is kept in the pruned directory of the keystore
+	backups, err := filepath.Glob(filepath.Join(ts.KeyStore.PrunedDir(), "*", store.Accounts, "X", "*"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	kp, err := ts.KeyStore.Read(backups[0])
+	require.NoError(t, err)
+	require.True(t, store.Match(apub, kp))
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+func createKeysRestoreCmd() *cobra.Command {
+	var params KeysRestoreParams
+	cmd := &cobra.Command{
+		Use:   "restore <backup or shares>...",
+		Short: "Restore seeds from a backup",
+		Long: `Restore seeds from a backup
+
+The arguments are a backup, or enough shares of a backup to reassemble it.
+Seeds are only restored for the JWTs in the store with the same public key.`,
+		Example: `nsc keys restore operator.backup
+nsc keys restore operator.share.1 operator.share.4 operator.share.5`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			cmd.Println(params.Table())
+			cmd.Printf("Success! - restored %d seed(s)\n", params.restored)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.passphraseFile, "passphrase-file", "", "", "file with the passphrase, prompted if not specified")
+	markSecret(cmd, "passphrase-file")
+
+	return cmd
+}
+
+func init() {
+	keysCmd.AddCommand(createKeysRestoreCmd())
+}
+
+// RestoredKey is a seed in a backup and the outcome of restoring it
+type RestoredKey struct {
+	BackupKey
+	PublicKey string
+	Status    string
+	kp        nkeys.KeyPair
+}
+
+type KeysRestoreParams struct {
+	passphraseFile string
+	data           []byte
+	keys           []*RestoredKey
+	restored       int
+}
+
+func (p *KeysRestoreParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysRestoreParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysRestoreParams) Load(ctx ActionCtx) error {
+	var backups [][]byte
+	var shares []SecretShare
+	for _, fp := range ctx.Args() {
+		d, err := ioutil.ReadFile(fp)
+		if err != nil {
+			return fmt.Errorf("error reading %q: %v", fp, err)
+		}
+		found := false
+		for {
+			var b *pem.Block
+			b, d = pem.Decode(d)
+			if b == nil {
+				break
+			}
+			switch b.Type {
+			case BackupType:
+				backups = append(backups, b.Bytes)
+			case BackupShareType:
+				x, err := strconv.Atoi(b.Headers["Share"])
+				if err != nil || x < 1 || x > 255 {
+					return fmt.Errorf("%q has an invalid share number", fp)
+				}
+				t, err := strconv.Atoi(b.Headers["Threshold"])
+				if err != nil || t < 2 || t > 255 {
+					return fmt.Errorf("%q has an invalid share threshold", fp)
+				}
+				shares = append(shares, SecretShare{X: byte(x), Threshold: byte(t), Data: b.Bytes})
+			default:
+				continue
+			}
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("%q doesn't contain a backup or a share", fp)
+		}
+	}
+
+	switch {
+	case len(backups) > 1 || (len(backups) == 1 && len(shares) > 0):
+		return errors.New("specify a single backup or the shares of a backup")
+	case len(backups) == 1:
+		p.data = backups[0]
+	default:
+		var err error
+		if p.data, err = CombineShares(shares); err != nil {
+			return fmt.Errorf("error reassembling the backup: %v", err)
+		}
+	}
+	return nil
+}
+
+func (p *KeysRestoreParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *KeysRestoreParams) Validate(ctx ActionCtx) error {
+	passphrase, err := readPassphrase(p.passphraseFile, false)
+	if err != nil {
+		return err
+	}
+	backup, err := DecryptBackup(p.data, passphrase)
+	if err != nil {
+		return err
+	}
+
+	s := ctx.StoreCtx().Store
+	for _, k := range backup.Keys {
+		rk := &RestoredKey{BackupKey: k}
+		p.keys = append(p.keys, rk)
+
+		kp, err := nkeys.FromSeed([]byte(k.Seed))
+		if err != nil {
+			rk.Status = "skipped - invalid seed"
+			continue
+		}
+		rk.PublicKey, _ = kp.PublicKey()
+		kind, err := store.KeyType(kp)
+		if err != nil || kind.String() != k.Kind {
+			rk.Status = "skipped - seed is not a " + k.Kind
+			continue
+		}
+		subject, err := p.subject(s, kind, k.Parent, k.Name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case subject == "":
+			rk.Status = "skipped - no JWT in the store"
+		case subject != rk.PublicKey:
+			rk.Status = "skipped - JWT has another public key"
+		default:
+			rk.kp = kp
+		}
+	}
+	return nil
+}
+
+// subject returns the public key of the JWT of an entity, or "" if the store doesn't have it
+func (p *KeysRestoreParams) subject(s *store.Store, kind nkeys.PrefixByte, parent string, name string) (string, error) {
+	var c jwt.Claims
+	var err error
+	switch kind {
+	case nkeys.PrefixByteOperator:
+		gc, err := s.LoadRootClaim()
+		if err != nil || gc == nil || gc.Name != name {
+			return "", err
+		}
+		return gc.Subject, nil
+	case nkeys.PrefixByteAccount:
+		if s.Has(store.Accounts, name, store.JwtName(name)) {
+			c, err = s.ReadAccountClaim(name)
+		}
+	case nkeys.PrefixByteUser:
+		if s.Has(store.Accounts, parent, store.Users, store.JwtName(name)) {
+			c, err = s.ReadUserClaim(parent, name)
+		}
+	case nkeys.PrefixByteCluster:
+		if s.Has(store.Clusters, name, store.JwtName(name)) {
+			c, err = s.ReadClusterClaim(name)
+		}
+	case nkeys.PrefixByteServer:
+		if s.Has(store.Clusters, parent, store.Servers, store.JwtName(name)) {
+			c, err = s.ReadServerClaim(parent, name)
+		}
+	}
+	if err != nil || c == nil {
+		return "", err
+	}
+	return c.Claims().Subject, nil
+}
+
+func (p *KeysRestoreParams) Run(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+	ks := ctx.StoreCtx().KeyStore
+	for _, k := range p.keys {
+		if k.kp == nil {
+			continue
+		}
+		fp, err := ks.Store(k.Name, k.kp, k.Parent)
+		if err != nil {
+			k.Status = fmt.Sprintf("failed - %v", err)
+			continue
+		}
+		if fp == "" {
+			k.Status = "already in the keystore"
+			continue
+		}
+		k.Status = "restored"
+		p.restored++
+		s.RecordChange(store.ClaimChange{Kind: SeedRestored, Name: k.Name, PublicKey: k.PublicKey})
+	}
+	return nil
+}
+
+func (p *KeysRestoreParams) Table() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle("Restored Seeds")
+	table.AddHeaders("Kind", "Name", "Public Key", "Status")
+	for _, k := range p.keys {
+		name := k.Name
+		if k.Parent != "" {
+			name = k.Parent + "/" + k.Name
+		}
+		table.AddRow(k.Kind, name, k.PublicKey, k.Status)
+	}
+	return table.Render()
+}
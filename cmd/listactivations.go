@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nsc/cli"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+// activations expiring within this window are highlighted
+const expiringSoon = 7 * 24 * time.Hour
+
+func createListActivationsCmd() *cobra.Command {
+	var params ListActivationsParams
+	cmd := &cobra.Command{
+		Use:          "activations",
+		Short:        "List activations issued by an account",
+		Example:      "nsc list activations --account A",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunAction(cmd, args, &params)
+		},
+	}
+	params.AccountContextParams.BindFlags(cmd)
+
+	return cmd
+}
+
+func init() {
+	listCmd.AddCommand(createListActivationsCmd())
+}
+
+type ListActivationsParams struct {
+	AccountContextParams
+	activations []*store.ActivationRecord
+}
+
+func (p *ListActivationsParams) SetDefaults(ctx ActionCtx) error {
+	p.AccountContextParams.SetDefaults(ctx)
+	return nil
+}
+
+func (p *ListActivationsParams) PreInteractive(ctx ActionCtx) error {
+	return p.AccountContextParams.Edit(ctx)
+}
+
+func (p *ListActivationsParams) Load(ctx ActionCtx) error {
+	var err error
+	if err = p.AccountContextParams.Validate(ctx); err != nil {
+		return err
+	}
+	p.activations, err = ctx.StoreCtx().Store.ListActivations(p.AccountContextParams.Name)
+	return err
+}
+
+func (p *ListActivationsParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ListActivationsParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ListActivationsParams) Run(ctx ActionCtx) error {
+	if len(p.activations) == 0 {
+		ctx.CurrentCmd().Printf("account %q has not issued any activations\n", p.AccountContextParams.Name)
+		return nil
+	}
+
+	now := time.Now()
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle(fmt.Sprintf("Activations issued by %s", p.AccountContextParams.Name))
+	table.AddHeaders("JTI", "Target Account", "Type", "Subject", "Expires", "Status")
+	for _, v := range p.activations {
+		expires := "No expiration"
+		if v.Expires > 0 {
+			expires = fmt.Sprintf("%s (%s)", UnixToDate(v.Expires), HumanizedDate(v.Expires))
+		}
+		status := "Active"
+		switch {
+		case v.IsRevoked():
+			status = "Revoked"
+		case v.RenewedBy != "":
+			status = fmt.Sprintf("Renewed by %s", v.RenewedBy)
+		case v.IsExpired(now.Unix()):
+			status = "Expired"
+			expires = cli.Bold(expires)
+		case v.Expires > 0 && v.Expires < now.Add(expiringSoon).Unix():
+			status = "Expiring"
+			expires = cli.Italic(expires)
+		}
+		table.AddRow(v.ID, v.Target, v.Type, v.Subject, expires, status)
+	}
+	ctx.CurrentCmd().Println(table.Render())
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GenerateActivationIsRecorded(t *testing.T) {
+	ts := NewTestStore(t, "list activations")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo.>", false)
+	ts.AddAccount(t, "B")
+
+	token := ts.GenerateActivation(t, "A", "foo.>", "B")
+	ac, err := jwt.DecodeActivationClaims(token)
+	require.NoError(t, err)
+
+	r, err := ts.Store.ReadActivation("A", ac.ID)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	require.Equal(t, ac.Subject, r.Target)
+	require.Equal(t, "foo.>", r.Subject)
+	require.Equal(t, token, r.Token)
+}
+
+func Test_ListActivations(t *testing.T) {
+	ts := NewTestStore(t, "list activations")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo.>", false)
+	ts.AddAccount(t, "B")
+
+	_, stderr, err := ExecuteCmd(createListActivationsCmd(), "--account", "A")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "account \"A\" has not issued any activations")
+
+	token := ts.GenerateActivation(t, "A", "foo.>", "B")
+	ac, err := jwt.DecodeActivationClaims(token)
+	require.NoError(t, err)
+
+	_, stderr, err = ExecuteCmd(createListActivationsCmd(), "--account", "A")
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, ac.ID)
+	require.Contains(t, stderr, ac.Subject)
+	require.Contains(t, stderr, "No expiration Active")
+}
+
+func Test_ListActivationsHighlightsExpiring(t *testing.T) {
+	ts := NewTestStore(t, "list activations")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo.>", false)
+	_, pub, _ := CreateAccountKey(t)
+	_, _, err := ExecuteCmd(createGenerateActivationCmd(), "--account", "A", "--target-account", pub, "--expiry", "2d")
+	require.NoError(t, err)
+
+	_, stderr, err := ExecuteCmd(createListActivationsCmd(), "--account", "A")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Expiring")
+}
@@ -0,0 +1,438 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+func createMigrateCmd() *cobra.Command {
+	var params MigrateParams
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the accounts and users of a nats-server config file",
+		Long: `Migrate the accounts and users of a nats-server config file
+
+Each account in the accounts block becomes an account in the current operator,
+with its exports, imports and users. Users in the authorization block are
+added to the account named by --global-account. Users keep their publish and
+subscribe permissions and get new nkeys, passwords are not migrated. Users
+configured with an nkey keep it, but have no creds file as their seed is not
+known.
+
+Exports restricted to accounts, and exports imported by another account in
+the config, become private exports, and activations are generated for the
+imports.
+
+Creds files for the new users are written to the creds directory.`,
+		Example: `nsc migrate --from server.conf
+nsc migrate --from server.conf --creds-dir /path/creds --dry-run`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			if len(params.plan) == 0 {
+				cmd.Println("No changes - the store matches the config")
+			} else if params.dryRun {
+				cmd.Printf("Dry run - %d change(s) not applied\n", len(params.plan))
+			} else {
+				cmd.Println(params.UsersTable())
+				cmd.Printf("Success! - migrated %d user(s)\n", len(params.users))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.from, "from", "f", "", "nats-server config file")
+	cmd.Flags().StringVarP(&params.global, "global-account", "", "global", "account for the users in the authorization block")
+	cmd.Flags().StringVarP(&params.credsDir, "creds-dir", "", "creds", "directory for the creds files")
+	cmd.Flags().BoolVarP(&params.dryRun, "dry-run", "", false, "show the changes without applying them")
+
+	return cmd
+}
+
+func init() {
+	GetRootCmd().AddCommand(createMigrateCmd())
+}
+
+// MigratedUser maps a user in the server config to the user created for it
+type MigratedUser struct {
+	Account   string
+	Username  string
+	Name      string
+	PublicKey string
+	Creds     string
+}
+
+type MigrateParams struct {
+	ApplyParams
+	from     string
+	global   string
+	credsDir string
+	users    []*MigratedUser
+}
+
+func (p *MigrateParams) SetDefaults(ctx ActionCtx) error {
+	if p.from == "" {
+		ctx.CurrentCmd().SilenceUsage = false
+		return errors.New("a config file is required")
+	}
+	return nil
+}
+
+func (p *MigrateParams) Load(ctx ActionCtx) error {
+	d, err := ioutil.ReadFile(p.from)
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", p.from, err)
+	}
+	conf, err := ParseServerConfig(string(d))
+	if err != nil {
+		return fmt.Errorf("error parsing %q: %v", p.from, err)
+	}
+	o, err := p.operatorSpec(ctx.StoreCtx().Operator.Name, conf)
+	if err != nil {
+		return fmt.Errorf("error migrating %q: %v", p.from, err)
+	}
+	p.spec = &Spec{Operators: []*OperatorSpec{o}}
+	if err := p.spec.Validate(); err != nil {
+		return err
+	}
+	return p.planSpec()
+}
+
+func (p *MigrateParams) Run(ctx ActionCtx) error {
+	if err := p.ApplyParams.Run(ctx); err != nil {
+		return err
+	}
+	if p.dryRun || len(p.plan) == 0 {
+		return nil
+	}
+
+	s := ctx.StoreCtx().Store
+	ks := ctx.StoreCtx().KeyStore
+	for _, u := range p.users {
+		uc, err := s.ReadUserClaim(u.Account, u.Name)
+		if err != nil {
+			return err
+		}
+		if uc == nil {
+			return fmt.Errorf("user %q was not created", childName(u.Account, u.Name))
+		}
+		u.PublicKey = uc.Subject
+
+		kp, err := ks.GetUserKey(u.Account, u.Name)
+		if err != nil {
+			return err
+		}
+		if kp == nil {
+			continue
+		}
+		seed, err := kp.Seed()
+		if err != nil {
+			return fmt.Errorf("error reading seed for user %q: %v", childName(u.Account, u.Name), err)
+		}
+		token, err := s.Read(store.Accounts, u.Account, store.Users, store.JwtName(u.Name))
+		if err != nil {
+			return err
+		}
+		dir := filepath.Join(p.credsDir, u.Account)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("error creating %q: %v", dir, err)
+		}
+		u.Creds = filepath.Join(dir, fmt.Sprintf("%s.creds", u.Name))
+		if err := ioutil.WriteFile(u.Creds, FormatConfig("User", string(token), string(seed)), 0600); err != nil {
+			return fmt.Errorf("error writing %q: %v", u.Creds, err)
+		}
+	}
+	return nil
+}
+
+// UsersTable renders the mapping of the config users to the new users
+func (p *MigrateParams) UsersTable() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle("Migrated Users")
+	table.AddHeaders("Account", "Config User", "User", "Public Key", "Creds")
+	for _, u := range p.users {
+		creds := u.Creds
+		if creds == "" {
+			creds = "-"
+		}
+		table.AddRow(u.Account, u.Username, u.Name, u.PublicKey, creds)
+	}
+	return table.Render()
+}
+
+// operatorSpec translates the authorization and accounts blocks of the config
+func (p *MigrateParams) operatorSpec(operator string, conf map[string]interface{}) (*OperatorSpec, error) {
+	o := &OperatorSpec{Name: operator}
+
+	if v, ok := conf["accounts"]; ok {
+		accounts, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("accounts must be a map")
+		}
+		var names []string
+		for n := range accounts {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			ac, ok := accounts[n].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("account %q must be a map", n)
+			}
+			a, err := p.accountSpec(n, ac, nil)
+			if err != nil {
+				return nil, fmt.Errorf("account %q: %v", n, err)
+			}
+			o.Accounts = append(o.Accounts, a)
+		}
+	}
+
+	if v, ok := conf["authorization"]; ok {
+		auth, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("authorization must be a map")
+		}
+		if _, ok := auth["user"]; ok {
+			auth["users"] = []interface{}{map[string]interface{}{"user": auth["user"], "permissions": auth["permissions"]}}
+		}
+		for _, a := range o.Accounts {
+			if a.Name == p.global {
+				return nil, fmt.Errorf("account %q in the config is the global account - use --global-account to pick another name", p.global)
+			}
+		}
+		a, err := p.accountSpec(p.global, auth, auth["default_permissions"])
+		if err != nil {
+			return nil, fmt.Errorf("authorization: %v", err)
+		}
+		if len(a.Users) > 0 {
+			o.Accounts = append(o.Accounts, a)
+		}
+	}
+
+	if len(o.Accounts) == 0 {
+		return nil, errors.New("no accounts or users found")
+	}
+	return o, linkImports(o)
+}
+
+func (p *MigrateParams) accountSpec(name string, conf map[string]interface{}, defaultPerms interface{}) (*AccountSpec, error) {
+	a := &AccountSpec{Name: name}
+	users, err := confList(conf["users"])
+	if err != nil {
+		return nil, fmt.Errorf("users: %v", err)
+	}
+	for _, v := range users {
+		uc, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("users must be maps")
+		}
+		u := &UserSpec{}
+		mu := &MigratedUser{Account: name}
+		if nk, ok := uc["nkey"].(string); ok {
+			if !nkeys.IsValidPublicUserKey(nk) {
+				return nil, fmt.Errorf("%q is not a user public key", nk)
+			}
+			u.Name, u.PublicKey, mu.Username = nk, nk, nk
+		} else if un, ok := uc["user"].(string); ok && un != "" {
+			u.Name, mu.Username = un, un
+		} else {
+			return nil, errors.New("a user doesn't have a user or nkey")
+		}
+		mu.Name = u.Name
+
+		perms := uc["permissions"]
+		if perms == nil {
+			perms = defaultPerms
+		}
+		if err := confPermissions(perms, u); err != nil {
+			return nil, fmt.Errorf("user %q: %v", u.Name, err)
+		}
+		a.Users = append(a.Users, u)
+		p.users = append(p.users, mu)
+	}
+
+	exports, err := confList(conf["exports"])
+	if err != nil {
+		return nil, fmt.Errorf("exports: %v", err)
+	}
+	for _, v := range exports {
+		ec, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("exports must be maps")
+		}
+		e := &ExportSpec{}
+		if s, ok := ec["stream"].(string); ok {
+			e.Subject = s
+		} else if s, ok := ec["service"].(string); ok {
+			e.Subject, e.Type = s, jwt.Service.String()
+		} else {
+			return nil, errors.New("an export doesn't have a stream or service subject")
+		}
+		restricted, err := confStrings(ec["accounts"])
+		if err != nil {
+			return nil, fmt.Errorf("export %q: %v", e.Subject, err)
+		}
+		e.Private = len(restricted) > 0
+		a.Exports = append(a.Exports, e)
+	}
+
+	imports, err := confList(conf["imports"])
+	if err != nil {
+		return nil, fmt.Errorf("imports: %v", err)
+	}
+	for _, v := range imports {
+		ic, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("imports must be maps")
+		}
+		src, ok := ic["stream"].(map[string]interface{})
+		to := ic["prefix"]
+		if !ok {
+			src, ok = ic["service"].(map[string]interface{})
+			to = ic["to"]
+		}
+		if !ok {
+			return nil, errors.New("an import doesn't have a stream or service")
+		}
+		im := &ImportSpec{}
+		im.Account, _ = src["account"].(string)
+		im.Subject, _ = src["subject"].(string)
+		im.To, _ = to.(string)
+		if im.Account == "" || im.Subject == "" {
+			return nil, errors.New("an import requires an account and subject")
+		}
+		a.Imports = append(a.Imports, im)
+	}
+	return a, nil
+}
+
+// linkImports checks that the imports are exported and makes the exports
+// private, as activations are generated for the imports
+func linkImports(o *OperatorSpec) error {
+	accounts := make(map[string]*AccountSpec)
+	for _, a := range o.Accounts {
+		accounts[a.Name] = a
+	}
+	for _, a := range o.Accounts {
+		for _, im := range a.Imports {
+			src, ok := accounts[im.Account]
+			if !ok {
+				return fmt.Errorf("account %q imports %q from account %q which is not in the config", a.Name, im.Subject, im.Account)
+			}
+			var export *ExportSpec
+			for _, e := range src.Exports {
+				if jwt.Subject(im.Subject).IsContainedIn(jwt.Subject(e.Subject)) {
+					export = e
+					break
+				}
+			}
+			if export == nil {
+				return fmt.Errorf("account %q imports %q which account %q doesn't export", a.Name, im.Subject, im.Account)
+			}
+			export.Private = true
+		}
+	}
+	return nil
+}
+
+// confPermissions reads the publish and subscribe permissions of a user,
+// which are subjects to allow or maps with allow and deny subjects
+func confPermissions(v interface{}, u *UserSpec) error {
+	if v == nil {
+		return nil
+	}
+	perms, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.New("permissions must be a map")
+	}
+	for k, pv := range perms {
+		var err error
+		switch k {
+		case "publish", "pub":
+			u.AllowPub, u.DenyPub, err = confPermission(pv)
+		case "subscribe", "sub":
+			u.AllowSub, u.DenySub, err = confPermission(pv)
+		default:
+			err = fmt.Errorf("unsupported permission %q", k)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func confPermission(v interface{}) ([]string, []string, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		allow, err := confStrings(v)
+		return allow, nil, err
+	}
+	allow, err := confStrings(m["allow"])
+	if err != nil {
+		return nil, nil, err
+	}
+	deny, err := confStrings(m["deny"])
+	if err != nil {
+		return nil, nil, err
+	}
+	return allow, deny, nil
+}
+
+func confList(v interface{}) ([]interface{}, error) {
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return t, nil
+	default:
+		return nil, errors.New("expected a list")
+	}
+}
+
+// confStrings reads a string or a list of strings
+func confStrings(v interface{}) ([]string, error) {
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+	a, err := confList(v)
+	if err != nil {
+		return nil, errors.New("expected a string or a list of strings")
+	}
+	var strs []string
+	for _, e := range a {
+		s, ok := e.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, found %v", e)
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
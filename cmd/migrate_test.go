@@ -0,0 +1,158 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/stretchr/testify/require"
+)
+
+const migrateConf = `
+port: 4222
+authorization {
+  default_permissions = { publish: "public.>" }
+  users = [
+    {user: admin, password: pwd, permissions: {publish: ">", subscribe: {allow: [">"], deny: ["secret.>"]}}}
+    {user: guest, password: guest}
+  ]
+}
+accounts {
+  A: {
+    users: [{user: a, password: a}]
+    exports: [
+      {stream: "a.>"}
+      {service: "help", accounts: [B]}
+    ]
+  }
+  B: {
+    users: [{nkey: %s}]
+    imports: [
+      {stream: {account: A, subject: "a.>"}, prefix: from_a}
+      {service: {account: A, subject: help}, to: a_help}
+    ]
+  }
+}
+`
+
+func writeConf(t *testing.T, ts *TestStore, conf string) string {
+	fp := filepath.Join(ts.Dir, "server.conf")
+	require.NoError(t, ioutil.WriteFile(fp, []byte(conf), 0600))
+	return fp
+}
+
+func Test_Migrate(t *testing.T) {
+	ts := NewTestStore(t, "migrate")
+	defer ts.Done(t)
+
+	ukp, err := nkeys.CreateUser()
+	require.NoError(t, err)
+	upk, err := ukp.PublicKey()
+	require.NoError(t, err)
+
+	conf := writeConf(t, ts, fmt.Sprintf(migrateConf, upk))
+	creds := filepath.Join(ts.Dir, "creds")
+	_, stderr, err := ExecuteCmd(createMigrateCmd(), "--from", conf, "--creds-dir", creds)
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "Success! - migrated 4 user(s)")
+
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Len(t, a.Exports, 2)
+	for _, e := range a.Exports {
+		// both exports are imported, so both are private
+		require.True(t, e.TokenReq, string(e.Subject))
+	}
+
+	b, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Len(t, b.Imports, 2)
+	for _, im := range b.Imports {
+		require.Equal(t, a.Subject, im.Account)
+		if im.Type == jwt.Service {
+			require.Equal(t, jwt.Subject("a_help"), im.To)
+		} else {
+			require.Equal(t, jwt.Subject("from_a"), im.To)
+		}
+	}
+
+	nk, err := ts.Store.ReadUserClaim("B", upk)
+	require.NoError(t, err)
+	require.Equal(t, upk, nk.Subject)
+	require.Contains(t, stderr, upk+" -")
+
+	admin, err := ts.Store.ReadUserClaim("global", "admin")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{">"}, admin.Pub.Allow)
+	require.ElementsMatch(t, []string{">"}, admin.Sub.Allow)
+	require.ElementsMatch(t, []string{"secret.>"}, admin.Sub.Deny)
+
+	guest, err := ts.Store.ReadUserClaim("global", "guest")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"public.>"}, guest.Pub.Allow)
+
+	d, err := ioutil.ReadFile(filepath.Join(creds, "global", "admin.creds"))
+	require.NoError(t, err)
+	require.Contains(t, string(d), "BEGIN NATS USER JWT")
+	require.Contains(t, string(d), "BEGIN USER NKEY SEED")
+	require.Contains(t, stderr, admin.Subject)
+
+	// the store matches the config
+	_, stderr, err = ExecuteCmd(createMigrateCmd(), "--from", conf, "--creds-dir", creds)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No changes - the store matches the config")
+}
+
+func Test_MigrateDryRun(t *testing.T) {
+	ts := NewTestStore(t, "migrate")
+	defer ts.Done(t)
+
+	conf := writeConf(t, ts, "authorization { user: a, password: b }")
+	_, stderr, err := ExecuteCmd(createMigrateCmd(), "--from", conf, "--dry-run")
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "migrate create user global/a")
+	require.Contains(t, stderr, "Dry run")
+	ac, err := ts.Store.ReadAccountClaim("global")
+	require.NoError(t, err)
+	require.Nil(t, ac)
+}
+
+func Test_MigrateErrors(t *testing.T) {
+	ts := NewTestStore(t, "migrate")
+	defer ts.Done(t)
+
+	tests := []struct {
+		conf string
+		err  string
+	}{
+		{"port: 4222", "no accounts or users found"},
+		{"accounts { A: { imports: [{stream: {account: X, subject: x}}] } }", `from account "X" which is not in the config`},
+		{"accounts { A: {}, B: { imports: [{stream: {account: A, subject: x}}] } }", `imports "x" which account "A" doesn't export`},
+		{"authorization { users: [{password: x}] }", "a user doesn't have a user or nkey"},
+		{"authorization { users: [{user: x, permissions: {admin: true}}] }", `unsupported permission "admin"`},
+	}
+	for _, tt := range tests {
+		_, _, err := ExecuteCmd(createMigrateCmd(), "--from", writeConf(t, ts, tt.conf))
+		require.Error(t, err, tt.conf)
+		require.Contains(t, err.Error(), tt.err, tt.conf)
+	}
+}
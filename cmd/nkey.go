@@ -0,0 +1,121 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+)
+
+// nkeyCmd represents the nkey command
+var nkeyCmd = &cobra.Command{
+	Use:   "nkey",
+	Short: "Generate, inspect, sign and verify with nkeys",
+	Long: `Generate, inspect, sign and verify with nkeys
+
+Keys can be specified as a path to a file with the key, or as the literal seed
+or public key.`,
+}
+
+func init() {
+	GetRootCmd().AddCommand(nkeyCmd)
+}
+
+var nkeyKinds = []nkeys.PrefixByte{
+	nkeys.PrefixByteOperator,
+	nkeys.PrefixByteAccount,
+	nkeys.PrefixByteUser,
+	nkeys.PrefixByteCluster,
+	nkeys.PrefixByteServer,
+}
+
+// ParseNKeyKind returns the prefix for the name of a kind of nkey
+func ParseNKeyKind(v string) (nkeys.PrefixByte, error) {
+	var names []string
+	for _, k := range nkeyKinds {
+		if strings.EqualFold(v, k.String()) {
+			return k, nil
+		}
+		names = append(names, k.String())
+	}
+	return 0, fmt.Errorf("invalid nkey type %q - one of %s", v, strings.Join(names, ", "))
+}
+
+// resolveNKey resolves a key from a path or a literal value
+func resolveNKey(v string) (nkeys.KeyPair, error) {
+	if v == "" {
+		return nil, fmt.Errorf("a key is required")
+	}
+	kp, err := store.ResolveKey(v)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving key: %v", err)
+	}
+	if _, err := store.KeyType(kp); err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+// NKeyData are the bytes signed or verified, read from a file or a nonce
+type NKeyData struct {
+	file  string
+	nonce string
+}
+
+func (p *NKeyData) BindFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&p.file, "file", "f", "", "path to the file with the data")
+	cmd.Flags().StringVarP(&p.nonce, "nonce", "", "", "nonce - the data is the nonce string")
+}
+
+func (p *NKeyData) Read() ([]byte, error) {
+	switch {
+	case p.file != "" && p.nonce != "":
+		return nil, fmt.Errorf("specify one of --file or --nonce")
+	case p.file != "":
+		d, err := ioutil.ReadFile(p.file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %q: %v", p.file, err)
+		}
+		return d, nil
+	case p.nonce != "":
+		return []byte(p.nonce), nil
+	default:
+		return nil, fmt.Errorf("specify --file or --nonce")
+	}
+}
+
+// EncodeSignature encodes a signature as the nats-server expects it in a connect
+func EncodeSignature(sig []byte) string {
+	return base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// DecodeSignature decodes a signature in the url or standard base64 encodings
+func DecodeSignature(v string) ([]byte, error) {
+	v = strings.TrimSpace(v)
+	if sig, err := base64.RawURLEncoding.DecodeString(v); err == nil {
+		return sig, nil
+	}
+	if sig, err := base64.StdEncoding.DecodeString(v); err == nil {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("signature is not base64 encoded")
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/cobra"
+)
+
+func createNKeyGenCmd() *cobra.Command {
+	var params NKeyGenParams
+	cmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate an nkey",
+		Long: `Generate an nkey
+
+The seed and the public key are printed on separate lines. Output files only
+contain the seed, like the keys in the keystore, and are only readable by the
+owner.`,
+		Example: `nsc nkey gen --type account
+nsc nkey gen --type user --output-file u.nk`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunStoreLessAction(cmd, args, &params); err != nil {
+				return err
+			}
+			if !IsStdOut(params.out) {
+				cmd.Printf("Success! - wrote %s key %s to %q\n", params.kind.String(), params.pub, params.out)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.kindArg, "type", "t", "", "key type - operator, account, user, cluster or server")
+	cmd.Flags().StringVarP(&params.out, "output-file", "o", "--", "output file, '--' is stdout")
+
+	return cmd
+}
+
+func init() {
+	nkeyCmd.AddCommand(createNKeyGenCmd())
+}
+
+type NKeyGenParams struct {
+	kindArg string
+	kind    nkeys.PrefixByte
+	out     string
+	seed    []byte
+	pub     string
+}
+
+func (p *NKeyGenParams) SetDefaults(ctx ActionCtx) error {
+	if p.kindArg == "" {
+		ctx.CurrentCmd().SilenceUsage = false
+		return fmt.Errorf("a key type is required")
+	}
+	return nil
+}
+
+func (p *NKeyGenParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyGenParams) Load(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyGenParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyGenParams) Validate(ctx ActionCtx) error {
+	var err error
+	p.kind, err = ParseNKeyKind(p.kindArg)
+	return err
+}
+
+func (p *NKeyGenParams) Run(ctx ActionCtx) error {
+	kp, err := nkeys.CreatePair(p.kind)
+	if err != nil {
+		return err
+	}
+	if p.seed, err = kp.Seed(); err != nil {
+		return err
+	}
+	if p.pub, err = kp.PublicKey(); err != nil {
+		return err
+	}
+	if IsStdOut(p.out) {
+		return Write(p.out, []byte(fmt.Sprintf("%s\n%s\n", p.seed, p.pub)))
+	}
+	return WriteSecret(p.out, p.seed)
+}
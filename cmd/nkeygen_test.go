@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NKeyGen(t *testing.T) {
+	out, _, err := ExecuteCmd(createNKeyGenCmd(), "--type", "account")
+	require.NoError(t, err)
+
+	lines := strings.Fields(out)
+	require.Len(t, lines, 2)
+	kp, err := nkeys.FromSeed([]byte(lines[0]))
+	require.NoError(t, err)
+	pk, err := kp.PublicKey()
+	require.NoError(t, err)
+	require.Equal(t, pk, lines[1])
+	require.True(t, nkeys.IsValidPublicAccountKey(pk))
+}
+
+func Test_NKeyGenOutputFile(t *testing.T) {
+	dir := MakeTempDir(t)
+	fp := filepath.Join(dir, "u.nk")
+	_, _, err := ExecuteCmd(createNKeyGenCmd(), "--type", "USER", "--output-file", fp)
+	require.NoError(t, err)
+
+	fi, err := os.Stat(fp)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	kp, err := resolveNKey(fp)
+	require.NoError(t, err)
+	pk, err := kp.PublicKey()
+	require.NoError(t, err)
+	require.True(t, nkeys.IsValidPublicUserKey(pk))
+	d, err := ioutil.ReadFile(fp)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(d), "SU"))
+
+	_, _, err = ExecuteCmd(createNKeyGenCmd(), "--type", "user", "--output-file", fp)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+}
+
+func Test_NKeyGenRequiresType(t *testing.T) {
+	tests := CmdTests{
+		{createNKeyGenCmd(), []string{"nkey", "gen"}, nil, []string{"a key type is required"}, true},
+		{createNKeyGenCmd(), []string{"nkey", "gen", "--type", "foo"}, nil, []string{"invalid nkey type \"foo\""}, true},
+	}
+	tests.Run(t, "root", "nkey")
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+func createNKeyInspectCmd() *cobra.Command {
+	var params NKeyInspectParams
+	cmd := &cobra.Command{
+		Use:   "inspect <file or key>",
+		Short: "Describe an nkey",
+		Example: `nsc nkey inspect ~/.nkeys/O/accounts/A/A.nk
+nsc nkey inspect AAB3...`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunStoreLessAction(cmd, args, &params); err != nil {
+				return err
+			}
+			return Write(params.out, []byte(params.Table()))
+		},
+	}
+	cmd.Flags().StringVarP(&params.out, "output-file", "o", "--", "output file, '--' is stdout")
+
+	return cmd
+}
+
+func init() {
+	nkeyCmd.AddCommand(createNKeyInspectCmd())
+}
+
+type NKeyInspectParams struct {
+	out     string
+	kind    nkeys.PrefixByte
+	pub     string
+	hasSeed bool
+}
+
+func (p *NKeyInspectParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyInspectParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyInspectParams) Load(ctx ActionCtx) error {
+	kp, err := resolveNKey(ctx.Args()[0])
+	if err != nil {
+		return err
+	}
+	if p.kind, err = store.KeyType(kp); err != nil {
+		return err
+	}
+	if p.pub, err = kp.PublicKey(); err != nil {
+		return err
+	}
+	_, err = kp.Seed()
+	p.hasSeed = err == nil
+	return nil
+}
+
+func (p *NKeyInspectParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyInspectParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyInspectParams) Run(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyInspectParams) Table() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle("NKey")
+	table.AddRow("Type", p.kind.String())
+	table.AddRow("Public Key", p.pub)
+	table.AddRow("Seed", fmt.Sprintf("%t", p.hasSeed))
+	return table.Render()
+}
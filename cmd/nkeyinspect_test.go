@@ -0,0 +1,49 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NKeyInspect(t *testing.T) {
+	dir := MakeTempDir(t)
+	seed, pk, kp := CreateClusterKey(t)
+	fp := StoreKey(t, kp, dir)
+
+	out, _, err := ExecuteCmd(createNKeyInspectCmd(), fp)
+	require.NoError(t, err)
+	out = StripTableDecorations(out)
+	require.Contains(t, out, "Type cluster")
+	require.Contains(t, out, "Public Key "+pk)
+	require.Contains(t, out, "Seed true")
+
+	out, _, err = ExecuteCmd(createNKeyInspectCmd(), string(seed))
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(out), "Public Key "+pk)
+
+	out, _, err = ExecuteCmd(createNKeyInspectCmd(), pk)
+	require.NoError(t, err)
+	out = StripTableDecorations(out)
+	require.Contains(t, out, "Type cluster")
+	require.Contains(t, out, "Seed false")
+
+	_, _, err = ExecuteCmd(createNKeyInspectCmd(), filepath.Join(dir, "missing.nk"))
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/cobra"
+)
+
+func createNKeySignCmd() *cobra.Command {
+	var params NKeySignParams
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign a file or a nonce with an nkey seed",
+		Long: `Sign a file or a nonce with an nkey seed
+
+The signature is base64 url encoded without padding, as the nats-server
+expects it in the connect of a client signing the server nonce.`,
+		Example: `nsc nkey sign --key ~/.nkeys/O/accounts/A/users/u.nk --nonce PXoWU7zWAMt75FY
+nsc nkey sign --key SUAM... --file data.txt --output-file data.sig`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunStoreLessAction(cmd, args, &params)
+		},
+	}
+	cmd.Flags().StringVarP(&params.key, "key", "k", "", "path to the seed or the seed")
+	cmd.Flags().StringVarP(&params.out, "output-file", "o", "--", "output file, '--' is stdout")
+	params.NKeyData.BindFlags(cmd)
+
+	return cmd
+}
+
+func init() {
+	nkeyCmd.AddCommand(createNKeySignCmd())
+}
+
+type NKeySignParams struct {
+	NKeyData
+	key  string
+	out  string
+	kp   nkeys.KeyPair
+	data []byte
+}
+
+func (p *NKeySignParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeySignParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeySignParams) Load(ctx ActionCtx) error {
+	var err error
+	if p.kp, err = resolveNKey(p.key); err != nil {
+		return err
+	}
+	p.data, err = p.NKeyData.Read()
+	return err
+}
+
+func (p *NKeySignParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeySignParams) Validate(ctx ActionCtx) error {
+	if _, err := p.kp.Seed(); err != nil {
+		return fmt.Errorf("signing requires a seed")
+	}
+	return nil
+}
+
+func (p *NKeySignParams) Run(ctx ActionCtx) error {
+	sig, err := p.kp.Sign(p.data)
+	if err != nil {
+		return err
+	}
+	return Write(p.out, []byte(EncodeSignature(sig)+"\n"))
+}
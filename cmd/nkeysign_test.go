@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NKeySignVerify(t *testing.T) {
+	dir := MakeTempDir(t)
+	_, pk, kp := CreateUserKey(t)
+	fp := StoreKey(t, kp, dir)
+
+	out, _, err := ExecuteCmd(createNKeySignCmd(), "--key", fp, "--nonce", "PXoWU7zWAMt75FY")
+	require.NoError(t, err)
+	sig := strings.TrimSpace(out)
+
+	// the server verifies the nonce signature of a client this way
+	d, err := DecodeSignature(sig)
+	require.NoError(t, err)
+	require.NoError(t, kp.Verify([]byte("PXoWU7zWAMt75FY"), d))
+
+	_, stderr, err := ExecuteCmd(createNKeyVerifyCmd(), "--key", pk, "--nonce", "PXoWU7zWAMt75FY", "--sig", sig)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "signature was verified")
+
+	_, _, err = ExecuteCmd(createNKeyVerifyCmd(), "--key", pk, "--nonce", "other", "--sig", sig)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signature is not valid")
+}
+
+func Test_NKeySignFile(t *testing.T) {
+	dir := MakeTempDir(t)
+	seed, pk, _ := CreateAccountKey(t)
+	data := filepath.Join(dir, "data.txt")
+	require.NoError(t, ioutil.WriteFile(data, []byte("hello"), 0600))
+	sigFile := filepath.Join(dir, "data.sig")
+
+	_, _, err := ExecuteCmd(createNKeySignCmd(), "--key", string(seed), "--file", data, "--output-file", sigFile)
+	require.NoError(t, err)
+
+	_, _, err = ExecuteCmd(createNKeyVerifyCmd(), "--key", pk, "--file", data, "--sig", sigFile)
+	require.NoError(t, err)
+
+	_, _, err = ExecuteCmd(createNKeySignCmd(), "--key", pk, "--file", data)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signing requires a seed")
+
+	_, _, err = ExecuteCmd(createNKeySignCmd(), "--key", string(seed))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "specify --file or --nonce")
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/cobra"
+)
+
+func createNKeyVerifyCmd() *cobra.Command {
+	var params NKeyVerifyParams
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the signature of a file or a nonce",
+		Example: `nsc nkey verify --key UDXU4R... --nonce PXoWU7zWAMt75FY --sig kN3N...
+nsc nkey verify --key u.nk --file data.txt --sig data.sig`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunStoreLessAction(cmd, args, &params); err != nil {
+				return err
+			}
+			cmd.Printf("Success! - the signature was verified by %s\n", params.pub)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.key, "key", "k", "", "path to the key, the public key or the seed")
+	cmd.Flags().StringVarP(&params.sigArg, "sig", "", "", "path to the signature or the signature")
+	params.NKeyData.BindFlags(cmd)
+
+	return cmd
+}
+
+func init() {
+	nkeyCmd.AddCommand(createNKeyVerifyCmd())
+}
+
+type NKeyVerifyParams struct {
+	NKeyData
+	key    string
+	sigArg string
+	kp     nkeys.KeyPair
+	pub    string
+	data   []byte
+	sig    []byte
+}
+
+func (p *NKeyVerifyParams) SetDefaults(ctx ActionCtx) error {
+	if p.sigArg == "" {
+		ctx.CurrentCmd().SilenceUsage = false
+		return fmt.Errorf("a signature is required")
+	}
+	return nil
+}
+
+func (p *NKeyVerifyParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyVerifyParams) Load(ctx ActionCtx) error {
+	var err error
+	if p.kp, err = resolveNKey(p.key); err != nil {
+		return err
+	}
+	if p.pub, err = p.kp.PublicKey(); err != nil {
+		return err
+	}
+	if p.data, err = p.NKeyData.Read(); err != nil {
+		return err
+	}
+
+	sig := p.sigArg
+	if _, err := os.Stat(sig); err == nil {
+		d, err := ioutil.ReadFile(sig)
+		if err != nil {
+			return fmt.Errorf("error reading %q: %v", sig, err)
+		}
+		sig = string(d)
+	}
+	p.sig, err = DecodeSignature(sig)
+	return err
+}
+
+func (p *NKeyVerifyParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyVerifyParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *NKeyVerifyParams) Run(ctx ActionCtx) error {
+	if err := p.kp.Verify(p.data, p.sig); err != nil {
+		return fmt.Errorf("the signature is not valid for %s", p.pub)
+	}
+	return nil
+}
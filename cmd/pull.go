@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+)
+
+func createPullCmd() *cobra.Command {
+	var params PullParams
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Pull account JWTs from a resolver",
+		Long: `Pull account JWTs from a resolver
+
+Accounts are fetched from the account url template of the cluster, or from the
+url specified by --account-url. If the local account changed since the last
+sync, or is newer than the one on the resolver, the account is not updated
+and the differences are shown. Use --force to overwrite the local version.
+
+Accounts that are not signed by the operator of the store are rejected.`,
+		Example: `nsc pull --cluster C
+nsc pull --account-url http://localhost:9090/jwt/v1/accounts/ --account A`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			cmd.Println(SyncSummary(fmt.Sprintf("Pull from %s", params.url), params.results))
+			return params.conflicts()
+		},
+	}
+	params.bindFlags(cmd)
+
+	return cmd
+}
+
+func init() {
+	GetRootCmd().AddCommand(createPullCmd())
+}
+
+type PullParams struct {
+	SyncParams
+}
+
+// pullOperator returns the operator that must sign the pulled accounts
+func pullOperator(s *store.Store) (*jwt.OperatorClaims, error) {
+	if !s.IsManaged() {
+		return ReadOperatorClaim(s)
+	}
+	if s.Info.OperatorKey == "" {
+		return nil, errors.New("the store doesn't have an operator key to verify the pulled accounts")
+	}
+	return jwt.NewOperatorClaims(s.Info.OperatorKey), nil
+}
+
+func (p *PullParams) Run(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+	oc, err := pullOperator(s)
+	if err != nil {
+		return err
+	}
+	for _, n := range p.names() {
+		ac := p.claims[n]
+		r := &SyncResult{Account: n}
+		p.results = append(p.results, r)
+
+		token, remote, err := fetchAccount(AccountURL(p.template, ac.Subject))
+		if err != nil {
+			r.Status, r.Error = SyncFailed, err.Error()
+			continue
+		}
+		if remote == nil {
+			r.Status = SyncMissing
+			continue
+		}
+		if remote.ID == ac.ID {
+			r.Status = SyncInSync
+			p.synced(ac)
+			continue
+		}
+		if remote.Subject != ac.Subject || remote.Name != n {
+			r.Status, r.Error = SyncFailed, fmt.Sprintf("resolver returned account %q (%s)", remote.Name, remote.Subject)
+			continue
+		}
+		if !oc.DidSign(remote) {
+			r.Status, r.Error = SyncFailed, fmt.Sprintf("account is signed by %s and not by the operator", remote.Issuer)
+			continue
+		}
+		// the local account is newer, or was modified since the last sync
+		last := p.lastSync(ac)
+		if !p.force && (ac.IssuedAt > remote.IssuedAt || (last != nil && last.ID != ac.ID)) {
+			r.Status = SyncConflict
+			if r.Diff, err = DiffClaims(ac, remote); err != nil {
+				r.Error = err.Error()
+			}
+			continue
+		}
+		if err := s.StoreClaim([]byte(token)); err != nil {
+			r.Status, r.Error = SyncFailed, err.Error()
+			continue
+		}
+		r.Status = SyncPulled
+		p.synced(remote)
+	}
+	return s.StoreSyncState(p.state)
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Pull(t *testing.T) {
+	ts := NewTestStore(t, "pull")
+	defer ts.Done(t)
+	r := NewResolver()
+	defer r.Close()
+
+	ts.AddAccount(t, "A")
+	_, stderr, err := ExecuteCmd(createPullCmd(), "--account-url", r.Template())
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "A not on resolver")
+
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	a.Tags.Add("remote")
+	opk, err := ts.KeyStore.GetOperatorKey("pull")
+	require.NoError(t, err)
+	token, err := a.Encode(opk)
+	require.NoError(t, err)
+	r.Set(a.Subject, token)
+
+	_, stderr, err = ExecuteCmd(createPullCmd(), "--account-url", r.Template())
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "A pulled")
+	a, err = ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Contains(t, a.Tags, "remote")
+
+	_, stderr, err = ExecuteCmd(createPullCmd(), "--account-url", r.Template())
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "A in sync")
+}
+
+func Test_PullConflict(t *testing.T) {
+	ts := NewTestStore(t, "pull")
+	defer ts.Done(t)
+	r := NewResolver()
+	defer r.Close()
+
+	ts.AddAccount(t, "A")
+	_, _, err := ExecuteCmd(createPushCmd(), "--account-url", r.Template())
+	require.NoError(t, err)
+
+	_, _, err = ExecuteCmd(createEditAccount(), "--account", "A", "--tag", "local")
+	require.NoError(t, err)
+	_, stderr, err := ExecuteCmd(createPullCmd(), "--account-url", r.Template())
+	require.Error(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "A conflict")
+	require.Contains(t, stderr, `Field Local Remote tags ["local"]`)
+
+	_, stderr, err = ExecuteCmd(createPullCmd(), "--account-url", r.Template(), "--force")
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "A pulled")
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Empty(t, a.Tags)
+}
+
+func Test_PullRejectsForeignOperator(t *testing.T) {
+	ts := NewTestStore(t, "pull")
+	defer ts.Done(t)
+	r := NewResolver()
+	defer r.Close()
+
+	ts.AddAccount(t, "A")
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	id := a.ID
+	a.Tags.Add("remote")
+	_, _, okp := CreateOperatorKey(t)
+	token, err := a.Encode(okp)
+	require.NoError(t, err)
+	r.Set(a.Subject, token)
+
+	_, stderr, err := ExecuteCmd(createPullCmd(), "--account-url", r.Template())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "1 account(s) could not be synced")
+	require.Contains(t, stderr, "not by the operator")
+
+	a, err = ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Equal(t, id, a.ID)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func createPushCmd() *cobra.Command {
+	var params PushParams
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Push account JWTs that changed since the last sync to a resolver",
+		Long: `Push account JWTs that changed since the last sync to a resolver
+
+Accounts are posted to the account url template of the cluster, or to the url
+specified by --account-url. If the resolver has a version of the account that
+is newer than the local one, or that changed since the last sync, the account
+is not pushed and the differences are shown. Use --force to overwrite it.`,
+		Example: `nsc push --cluster C
+nsc push --account-url http://localhost:9090/jwt/v1/accounts/ --account A`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			cmd.Println(SyncSummary(fmt.Sprintf("Push to %s", params.url), params.results))
+			return params.conflicts()
+		},
+	}
+	params.bindFlags(cmd)
+
+	return cmd
+}
+
+func init() {
+	GetRootCmd().AddCommand(createPushCmd())
+}
+
+type PushParams struct {
+	SyncParams
+}
+
+func (p *PushParams) Run(ctx ActionCtx) error {
+	for _, n := range p.names() {
+		ac := p.claims[n]
+		r := &SyncResult{Account: n}
+		p.results = append(p.results, r)
+
+		last := p.lastSync(ac)
+		if !p.force && last != nil && last.ID == ac.ID {
+			r.Status = SyncSkipped
+			continue
+		}
+
+		url := AccountURL(p.template, ac.Subject)
+		_, remote, err := fetchAccount(url)
+		if err != nil {
+			r.Status, r.Error = SyncFailed, err.Error()
+			continue
+		}
+		if remote != nil && remote.ID == ac.ID {
+			r.Status = SyncInSync
+			p.synced(ac)
+			continue
+		}
+		// the remote is newer, or was modified by someone else since the last sync
+		if remote != nil && !p.force && (remote.IssuedAt > ac.IssuedAt || (last != nil && last.ID != remote.ID)) {
+			r.Status = SyncConflict
+			if r.Diff, err = DiffClaims(ac, remote); err != nil {
+				r.Error = err.Error()
+			}
+			continue
+		}
+		if err := postAccount(url, p.tokens[n]); err != nil {
+			r.Status, r.Error = SyncFailed, err.Error()
+			continue
+		}
+		r.Status = SyncPushed
+		p.synced(ac)
+	}
+	return ctx.StoreCtx().Store.StoreSyncState(p.state)
+}
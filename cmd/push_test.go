@@ -0,0 +1,177 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+// Resolver is an in-memory account resolver for tests
+type Resolver struct {
+	sync.Mutex
+	*httptest.Server
+	accounts map[string]string
+}
+
+func NewResolver() *Resolver {
+	r := &Resolver{accounts: make(map[string]string)}
+	r.Server = httptest.NewServer(r)
+	return r
+}
+
+func (r *Resolver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+	pk := strings.TrimPrefix(req.URL.Path, jwtServerAccountsPath)
+	switch req.Method {
+	case http.MethodGet:
+		token, ok := r.accounts[pk]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write([]byte(token))
+	case http.MethodPost:
+		d, _ := ioutil.ReadAll(req.Body)
+		r.accounts[pk] = string(d)
+	}
+}
+
+func (r *Resolver) Template() string {
+	return r.URL + jwtServerAccountsPath
+}
+
+func (r *Resolver) Set(pk string, token string) {
+	r.Lock()
+	defer r.Unlock()
+	r.accounts[pk] = token
+}
+
+func (r *Resolver) Get(t *testing.T, pk string) *jwt.AccountClaims {
+	r.Lock()
+	defer r.Unlock()
+	token, ok := r.accounts[pk]
+	if !ok {
+		return nil
+	}
+	ac, err := jwt.DecodeAccountClaims(token)
+	require.NoError(t, err)
+	return ac
+}
+
+func Test_AccountURL(t *testing.T) {
+	require.Equal(t, "http://a/jwt/v1/accounts/X", AccountURL("http://a/jwt/v1/accounts/", "X"))
+	require.Equal(t, "http://a/jwt/v1/accounts/X", AccountURL("http://a/jwt/v1/accounts", "X"))
+	require.Equal(t, "http://a/X/jwt", AccountURL("http://a/%s/jwt", "X"))
+}
+
+func Test_Push(t *testing.T) {
+	ts := NewTestStore(t, "push")
+	defer ts.Done(t)
+	r := NewResolver()
+	defer r.Close()
+
+	ts.AddAccount(t, "A")
+	ts.AddAccount(t, "B")
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	b, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+
+	_, stderr, err := ExecuteCmd(createPushCmd(), "--account-url", r.Template())
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "A pushed")
+	require.Contains(t, stderr, "B pushed")
+	require.Equal(t, a.ID, r.Get(t, a.Subject).ID)
+	require.Equal(t, b.ID, r.Get(t, b.Subject).ID)
+
+	_, stderr, err = ExecuteCmd(createPushCmd(), "--account-url", r.Template())
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "A unchanged since last sync")
+	require.Contains(t, stderr, "B unchanged since last sync")
+
+	_, _, err = ExecuteCmd(createEditAccount(), "--account", "A", "--tag", "local")
+	require.NoError(t, err)
+	_, stderr, err = ExecuteCmd(createPushCmd(), "--account-url", r.Template())
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "A pushed")
+	require.Contains(t, stderr, "B unchanged since last sync")
+	require.Contains(t, r.Get(t, a.Subject).Tags, "local")
+}
+
+func Test_PushConflict(t *testing.T) {
+	ts := NewTestStore(t, "push")
+	defer ts.Done(t)
+	r := NewResolver()
+	defer r.Close()
+
+	ts.AddAccount(t, "A")
+	_, _, err := ExecuteCmd(createPushCmd(), "--account-url", r.Template())
+	require.NoError(t, err)
+
+	// someone else updates the resolver
+	a, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	a.Tags.Add("remote")
+	opk, err := ts.KeyStore.GetOperatorKey("push")
+	require.NoError(t, err)
+	token, err := a.Encode(opk)
+	require.NoError(t, err)
+	r.Set(a.Subject, token)
+
+	_, _, err = ExecuteCmd(createEditAccount(), "--account", "A", "--tag", "local")
+	require.NoError(t, err)
+	_, stderr, err := ExecuteCmd(createPushCmd(), "--account-url", r.Template())
+	require.Error(t, err)
+	require.Equal(t, "1 account(s) could not be synced", err.Error())
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "A conflict")
+	require.Contains(t, stderr, `Conflict in account "A"`)
+	require.Contains(t, stderr, `tags ["local"] ["remote"]`)
+
+	_, stderr, err = ExecuteCmd(createPushCmd(), "--account-url", r.Template(), "--force")
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "A pushed")
+	require.Contains(t, r.Get(t, a.Subject).Tags, "local")
+}
+
+func Test_PushUsesClusterTemplate(t *testing.T) {
+	ts := NewTestStore(t, "push")
+	defer ts.Done(t)
+	r := NewResolver()
+	defer r.Close()
+
+	ts.AddAccount(t, "A")
+	_, _, err := ExecuteCmd(createPushCmd())
+	require.Error(t, err)
+
+	_, _, err = ExecuteCmd(createAddClusterCmd(), "--name", "C", "--account-url-template", r.Template())
+	require.NoError(t, err)
+	_, stderr, err := ExecuteCmd(createPushCmd(), "--cluster", "C")
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "A pushed")
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+const (
+	RefreshUnchanged   = "unchanged"
+	RefreshChanged     = "changed"
+	RefreshExpired     = "expired"
+	RefreshUnreachable = "unreachable"
+)
+
+func createRefreshImportsCmd() *cobra.Command {
+	var params RefreshImportsParams
+	cmd := &cobra.Command{
+		Use:   "imports",
+		Short: "Download all remote activations referenced by imports",
+		Example: `nsc refresh imports
+nsc refresh imports --account A`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			if len(params.imports) == 0 {
+				cmd.Println("No imports reference remote activations")
+				return nil
+			}
+			cmd.Println(params.Describe())
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.account, "account", "a", "", "only refresh imports in the specified account")
+
+	return cmd
+}
+
+func init() {
+	refreshCmd.AddCommand(createRefreshImportsCmd())
+}
+
+// RemoteImport is an import whose activation is retrieved from an url
+type RemoteImport struct {
+	Account string
+	Import  *jwt.Import
+	Status  string
+	Expires int64
+	Error   error
+}
+
+type RefreshImportsParams struct {
+	account string
+	imports []*RemoteImport
+}
+
+func (p *RefreshImportsParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RefreshImportsParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RefreshImportsParams) Load(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+	accounts, err := s.ListSubContainers(store.Accounts)
+	if err != nil {
+		return err
+	}
+	if p.account != "" {
+		if !s.Has(store.Accounts, p.account, store.JwtName(p.account)) {
+			return fmt.Errorf("account %q is not defined in the current context", p.account)
+		}
+		accounts = []string{p.account}
+	}
+	for _, a := range accounts {
+		ac, err := s.ReadAccountClaim(a)
+		if err != nil {
+			return err
+		}
+		if ac == nil {
+			continue
+		}
+		for _, im := range ac.Imports {
+			if NewImportDescriber(*im).IsRemoteImport() {
+				p.imports = append(p.imports, &RemoteImport{Account: a, Import: im})
+			}
+		}
+	}
+	return nil
+}
+
+func (p *RefreshImportsParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RefreshImportsParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RefreshImportsParams) Run(ctx ActionCtx) error {
+	// imports sharing an activation url are fetched once, concurrent fetches
+	// of the same url would race on its cache entry
+	var urls []string
+	byURL := make(map[string][]*RemoteImport)
+	for _, v := range p.imports {
+		u := v.Import.Token
+		if _, ok := byURL[u]; !ok {
+			urls = append(urls, u)
+		}
+		byURL[u] = append(byURL[u], v)
+	}
+
+	cache := NewActivationCache(ctx.StoreCtx().Store, false)
+	var wg sync.WaitGroup
+	for _, u := range urls {
+		wg.Add(1)
+		go func(imports []*RemoteImport) {
+			defer wg.Done()
+			ri := imports[0]
+			ri.refresh(cache)
+			for _, v := range imports[1:] {
+				v.Status = ri.Status
+				v.Expires = ri.Expires
+				v.Error = ri.Error
+			}
+		}(byURL[u])
+	}
+	wg.Wait()
+	return nil
+}
+
+func (ri *RemoteImport) refresh(cache *ActivationCache) {
+	r, err := cache.Fetch(ri.Import.Token)
+	if err != nil {
+		ri.Status = RefreshUnreachable
+		ri.Error = err
+		return
+	}
+	ac, err := jwt.DecodeActivationClaims(r.Token)
+	if err != nil {
+		ri.Status = RefreshUnreachable
+		ri.Error = err
+		return
+	}
+	ri.Expires = ac.Expires
+	switch {
+	case ac.Expires > 0 && ac.Expires < time.Now().Unix():
+		ri.Status = RefreshExpired
+	case r.Changed:
+		ri.Status = RefreshChanged
+	default:
+		ri.Status = RefreshUnchanged
+	}
+}
+
+func (p *RefreshImportsParams) Describe() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle("Remote Activations")
+	table.AddHeaders("Account", "Subject", "URL", "Expires", "Status")
+	for _, v := range p.imports {
+		expires := ""
+		if v.Expires > 0 {
+			expires = HumanizedDate(v.Expires)
+		}
+		status := v.Status
+		if v.Error != nil {
+			status = fmt.Sprintf("%s - %v", v.Status, v.Error)
+		}
+		table.AddRow(v.Account, string(v.Import.Subject), v.Import.Token, expires, status)
+	}
+	return table.Render()
+}
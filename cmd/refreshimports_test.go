@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RefreshImportsNone(t *testing.T) {
+	ts := NewTestStore(t, "refresh")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	_, stderr, err := ExecuteCmd(createRefreshImportsCmd())
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No imports reference remote activations")
+}
+
+func Test_RefreshImports(t *testing.T) {
+	ts := NewTestStore(t, "refresh")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo", false)
+	ts.AddExport(t, "A", jwt.Stream, "bar", false)
+	ts.AddAccount(t, "B")
+
+	foo := NewActivationServer(signActivation(t, ts, "A", "foo", "B"))
+	defer foo.Close()
+	bar := NewActivationServer(signActivation(t, ts, "A", "bar", "B"))
+
+	_, _, err := ExecuteCmd(createAddImportCmd(), "--account", "B", "--token", foo.URL)
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createAddImportCmd(), "--account", "B", "--token", bar.URL)
+	require.NoError(t, err)
+
+	_, _, err = ExecuteCmd(createRefreshImportsCmd())
+	require.NoError(t, err)
+
+	foo.SetToken(reissueActivation(t, ts, "A", foo.token))
+	bar.Close()
+
+	_, stderr, err := ExecuteCmd(createRefreshImportsCmd(), "--account", "B")
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Regexp(t, "B foo "+foo.URL+" In \\d+ \\w+ changed", stderr)
+	require.Contains(t, stderr, "B bar "+bar.URL+" unreachable")
+
+	_, stderr, err = ExecuteCmd(createRefreshImportsCmd(), "--account", "B")
+	require.NoError(t, err)
+	require.Regexp(t, "B foo "+foo.URL+" In \\d+ \\w+ unchanged", StripTableDecorations(stderr))
+}
+
+func Test_RefreshImportsSharedURL(t *testing.T) {
+	ts := NewTestStore(t, "refresh")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo", false)
+	ts.AddAccount(t, "B")
+	as := NewActivationServer(signActivation(t, ts, "A", "foo", "B"))
+	defer as.Close()
+
+	var p RefreshImportsParams
+	for _, s := range []string{"foo", "foo.bar", "foo.baz"} {
+		im := &jwt.Import{Subject: jwt.Subject(s), Token: as.URL}
+		p.imports = append(p.imports, &RemoteImport{Account: "B", Import: im})
+	}
+	ctx, err := NewActx(nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, p.Run(ctx))
+
+	requests, _ := as.Requests()
+	require.Equal(t, 1, requests)
+	for _, ri := range p.imports {
+		require.NoError(t, ri.Error)
+		require.Equal(t, RefreshChanged, ri.Status)
+	}
+}
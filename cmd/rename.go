@@ -0,0 +1,367 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cli"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+)
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Rename accounts, users, clusters and servers",
+	Long: `Rename accounts, users, clusters and servers
+
+The JWT is re-signed with the new name, and the JWT and the seed are moved to
+the new name. The public key of the entity doesn't change.`,
+}
+
+func init() {
+	GetRootCmd().AddCommand(renameCmd)
+	renameCmd.AddCommand(createRenameCmd(nkeys.PrefixByteAccount))
+	renameCmd.AddCommand(createRenameCmd(nkeys.PrefixByteUser))
+	renameCmd.AddCommand(createRenameCmd(nkeys.PrefixByteCluster))
+	renameCmd.AddCommand(createRenameCmd(nkeys.PrefixByteServer))
+}
+
+func createRenameCmd(kind nkeys.PrefixByte) *cobra.Command {
+	var params RenameParams
+	params.kind = kind
+	cmd := &cobra.Command{
+		Use:          kind.String(),
+		Short:        fmt.Sprintf("Rename a %s", kind.String()),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			cmd.Printf("Success! - renamed %s %q to %q\n", kind.String(), params.from, params.to)
+			params.sync.Print(cmd)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.to, "to", "", "", fmt.Sprintf("new %s name", kind.String()))
+
+	switch kind {
+	case nkeys.PrefixByteAccount:
+		cmd.Example = `nsc rename account --account A --to B
+nsc rename account --account AAB3 --to B`
+		params.AccountContextParams.BindFlags(cmd)
+	case nkeys.PrefixByteUser:
+		cmd.Example = `nsc rename user --account A --user u --to v`
+		params.AccountContextParams.BindFlags(cmd)
+		cmd.Flags().StringVarP(&params.name, "user", "u", "", "user name or public key prefix")
+	case nkeys.PrefixByteCluster:
+		cmd.Example = `nsc rename cluster --cluster C --to D`
+		params.ClusterContextParams.BindFlags(cmd)
+	case nkeys.PrefixByteServer:
+		cmd.Example = `nsc rename server --cluster C --server s --to t`
+		params.ClusterContextParams.BindFlags(cmd)
+		cmd.Flags().StringVarP(&params.name, "server", "s", "", "server name or public key prefix")
+	}
+	return cmd
+}
+
+// RenameParams renames an account, user, cluster or server. Users and servers
+// are named by name, accounts and clusters by their context params.
+type RenameParams struct {
+	AccountContextParams
+	ClusterContextParams
+	SignerParams
+	kind  nkeys.PrefixByte
+	name  string
+	from  string
+	to    string
+	claim jwt.Claims
+	sync  *ServerSync
+}
+
+func (p *RenameParams) isAccount() bool {
+	return p.kind == nkeys.PrefixByteAccount || p.kind == nkeys.PrefixByteUser
+}
+
+func (p *RenameParams) SetDefaults(ctx ActionCtx) error {
+	switch p.kind {
+	case nkeys.PrefixByteAccount:
+		p.AccountContextParams.SetDefaults(ctx)
+		p.SignerParams.SetDefaults(nkeys.PrefixByteOperator, true, ctx)
+	case nkeys.PrefixByteUser:
+		p.AccountContextParams.SetDefaults(ctx)
+		p.SignerParams.SetDefaults(nkeys.PrefixByteAccount, true, ctx)
+	case nkeys.PrefixByteCluster:
+		p.ClusterContextParams.SetDefaults(ctx)
+		p.SignerParams.SetDefaults(nkeys.PrefixByteOperator, false, ctx)
+	case nkeys.PrefixByteServer:
+		p.ClusterContextParams.SetDefaults(ctx)
+		p.SignerParams.SetDefaults(nkeys.PrefixByteCluster, false, ctx)
+	}
+
+	if !InteractiveFlag && p.to == "" {
+		ctx.CurrentCmd().SilenceUsage = false
+		return fmt.Errorf("a new %s name is required", p.kind.String())
+	}
+	return nil
+}
+
+func (p *RenameParams) PreInteractive(ctx ActionCtx) error {
+	var err error
+	switch p.kind {
+	case nkeys.PrefixByteAccount:
+		err = p.AccountContextParams.Edit(ctx)
+	case nkeys.PrefixByteUser:
+		if err = p.AccountContextParams.Edit(ctx); err == nil && p.name == "" {
+			p.name, err = ctx.StoreCtx().PickUser(p.AccountContextParams.Name)
+		}
+	case nkeys.PrefixByteCluster:
+		err = p.ClusterContextParams.Edit(ctx)
+	case nkeys.PrefixByteServer:
+		if err = p.ClusterContextParams.Edit(ctx); err == nil && p.name == "" {
+			p.name, err = ctx.StoreCtx().PickServer(p.ClusterContextParams.Name)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	p.to, err = cli.Prompt(fmt.Sprintf("new %s name", p.kind.String()), p.to, true, func(v string) error {
+		return store.ValidateName(p.kind.String(), v)
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.SignerParams.Edit(ctx)
+}
+
+func (p *RenameParams) Load(ctx ActionCtx) error {
+	var err error
+	sctx := ctx.StoreCtx()
+	s := sctx.Store
+
+	if p.isAccount() {
+		if err = p.AccountContextParams.Validate(ctx); err != nil {
+			return err
+		}
+		sctx.Account.Name = p.AccountContextParams.Name
+	} else {
+		if err = p.ClusterContextParams.Validate(ctx); err != nil {
+			return err
+		}
+		sctx.Cluster.Name = p.ClusterContextParams.Name
+	}
+
+	switch p.kind {
+	case nkeys.PrefixByteAccount:
+		p.from = p.AccountContextParams.Name
+		if !s.Has(store.Accounts, p.from, store.JwtName(p.from)) {
+			return fmt.Errorf("account %q not found", p.from)
+		}
+		p.claim, err = s.ReadAccountClaim(p.from)
+	case nkeys.PrefixByteCluster:
+		p.from = p.ClusterContextParams.Name
+		if !s.Has(store.Clusters, p.from, store.JwtName(p.from)) {
+			return fmt.Errorf("cluster %q not found", p.from)
+		}
+		p.claim, err = s.ReadClusterClaim(p.from)
+	case nkeys.PrefixByteUser:
+		account := p.AccountContextParams.Name
+		if p.name == "" {
+			if n := sctx.DefaultUser(account); n != nil {
+				p.name = *n
+			}
+		}
+		if p.name == "" {
+			ctx.CurrentCmd().SilenceUsage = false
+			return fmt.Errorf("user name is required")
+		}
+		if p.from, err = s.ResolveUser(account, p.name); err != nil {
+			return err
+		}
+		if !s.Has(store.Accounts, account, store.Users, store.JwtName(p.from)) {
+			return fmt.Errorf("user %q not found", p.from)
+		}
+		p.claim, err = s.ReadUserClaim(account, p.from)
+	case nkeys.PrefixByteServer:
+		cluster := p.ClusterContextParams.Name
+		if p.name == "" {
+			if n := sctx.DefaultServer(cluster); n != nil {
+				p.name = *n
+			}
+		}
+		if p.name == "" {
+			ctx.CurrentCmd().SilenceUsage = false
+			return fmt.Errorf("server name is required")
+		}
+		if p.from, err = s.ResolveServer(cluster, p.name); err != nil {
+			return err
+		}
+		if !s.Has(store.Clusters, cluster, store.Servers, store.JwtName(p.from)) {
+			return fmt.Errorf("server %q not found", p.from)
+		}
+		p.claim, err = s.ReadServerClaim(cluster, p.from)
+	}
+	return err
+}
+
+func (p *RenameParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RenameParams) Validate(ctx ActionCtx) error {
+	if err := store.ValidateName(p.kind.String(), p.to); err != nil {
+		return err
+	}
+	if p.to == p.from {
+		return fmt.Errorf("%s is already named %q", p.kind.String(), p.to)
+	}
+
+	s := ctx.StoreCtx().Store
+	var exists bool
+	switch p.kind {
+	case nkeys.PrefixByteAccount:
+		exists = s.Has(store.Accounts, p.to)
+	case nkeys.PrefixByteUser:
+		exists = s.Has(store.Accounts, p.AccountContextParams.Name, store.Users, store.JwtName(p.to))
+	case nkeys.PrefixByteCluster:
+		exists = s.Has(store.Clusters, p.to)
+	case nkeys.PrefixByteServer:
+		exists = s.Has(store.Clusters, p.ClusterContextParams.Name, store.Servers, store.JwtName(p.to))
+	}
+	if exists {
+		return fmt.Errorf("%s %q already exists", p.kind.String(), p.to)
+	}
+
+	if err := p.SignerParams.Resolve(ctx); err != nil {
+		return err
+	}
+	if p.signerKP == nil {
+		return fmt.Errorf("%s key is required to sign the %s", p.SignerParams.kind.String(), p.kind.String())
+	}
+	if !store.Match(p.claim.Claims().Issuer, p.signerKP) {
+		return fmt.Errorf("the %s key doesn't match the issuer of the %s", p.SignerParams.kind.String(), p.kind.String())
+	}
+	return nil
+}
+
+func (p *RenameParams) Run(ctx ActionCtx) error {
+	sctx := ctx.StoreCtx()
+	s := sctx.Store
+
+	p.claim.Claims().Name = p.to
+	token, err := p.claim.Encode(p.signerKP)
+	if err != nil {
+		return err
+	}
+
+	parent := p.AccountContextParams.Name
+	switch p.kind {
+	case nkeys.PrefixByteAccount:
+		if err := s.Rename(p.to, store.Accounts, p.from); err != nil {
+			return err
+		}
+		if err := p.moveClaim(s, token, store.Accounts, p.to, store.JwtName(p.from)); err != nil {
+			return err
+		}
+	case nkeys.PrefixByteUser:
+		if err := p.moveClaim(s, token, store.Accounts, parent, store.Users, store.JwtName(p.from)); err != nil {
+			return err
+		}
+	case nkeys.PrefixByteCluster:
+		if err := s.Rename(p.to, store.Clusters, p.from); err != nil {
+			return err
+		}
+		if err := p.moveClaim(s, token, store.Clusters, p.to, store.JwtName(p.from)); err != nil {
+			return err
+		}
+	case nkeys.PrefixByteServer:
+		parent = p.ClusterContextParams.Name
+		if err := p.moveClaim(s, token, store.Clusters, parent, store.Servers, store.JwtName(p.from)); err != nil {
+			return err
+		}
+	}
+
+	if err := sctx.KeyStore.Rename(p.kind, parent, p.from, p.to); err != nil {
+		return fmt.Errorf("renamed the %s but not its key: %v", p.kind.String(), err)
+	}
+	if p.kind == nkeys.PrefixByteCluster {
+		// servers embed the cluster JWT
+		if p.sync, err = SyncServers(ctx, p.to, token); err != nil {
+			return err
+		}
+	}
+	if p.renameInContexts(s.Dir) {
+		return GetConfig().Save()
+	}
+	return nil
+}
+
+// renameInContexts renames the account or cluster in the current context and in
+// the saved contexts of the store in dir, returns true if any context changed
+func (p *RenameParams) renameInContexts(dir string) bool {
+	config := GetConfig()
+	changed := p.renameIn(&config.ContextConfig, dir)
+	if savedContext != nil {
+		// the context replaced by --context is the one that is saved
+		changed = p.renameIn(&savedContext.ContextConfig, dir) || changed
+	}
+	for k, c := range config.Contexts {
+		if p.renameIn(&c, dir) {
+			config.Contexts[k] = c
+			changed = true
+		}
+	}
+	return changed
+}
+
+// renameIn renames the account or cluster in the context if it refers to the store in dir
+func (p *RenameParams) renameIn(c *ContextConfig, dir string) bool {
+	if c.StoreRoot == "" || !sameDir(filepath.Join(c.StoreRoot, c.Operator), dir) {
+		return false
+	}
+	switch {
+	case p.kind == nkeys.PrefixByteAccount && c.Account == p.from:
+		c.Account = p.to
+	case p.kind == nkeys.PrefixByteCluster && c.Cluster == p.from:
+		c.Cluster = p.to
+	default:
+		return false
+	}
+	return true
+}
+
+func sameDir(a string, b string) bool {
+	a, err := filepath.Abs(a)
+	if err != nil {
+		return false
+	}
+	b, err = filepath.Abs(b)
+	return err == nil && a == b
+}
+
+// moveClaim stores the renamed claim and deletes the JWT at the old name
+func (p *RenameParams) moveClaim(s *store.Store, token string, old ...string) error {
+	if err := s.StoreClaim([]byte(token)); err != nil {
+		return err
+	}
+	return s.Delete(old...)
+}
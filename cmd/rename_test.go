@@ -0,0 +1,164 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RenameAccount(t *testing.T) {
+	ts := NewTestStore(t, "rename account")
+	defer ts.Done(t)
+
+	ts.AddUser(t, "A", "a")
+	ForceAccount(t, "A")
+	pk, err := ts.KeyStore.GetAccountPublicKey("A")
+	require.NoError(t, err)
+	config := GetConfig()
+	require.NoError(t, config.AddContext("dev", config.ContextConfig))
+	other := config.ContextConfig
+	other.StoreRoot = MakeTempDir(t)
+	require.NoError(t, config.AddContext("other", other))
+
+	_, _, err = ExecuteCmd(createRenameCmd(nkeys.PrefixByteAccount), "--account", "A", "--to", "B")
+	require.NoError(t, err)
+	// saved contexts of the store follow the rename
+	require.Equal(t, "B", config.Contexts["dev"].Account)
+	require.Equal(t, "A", config.Contexts["other"].Account)
+
+	require.False(t, ts.Store.Has(store.Accounts, "A"))
+	ac, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Equal(t, "B", ac.Name)
+	require.Equal(t, pk, ac.Subject)
+	require.False(t, ts.Store.Has(store.Accounts, "B", store.JwtName("A")))
+
+	kp, err := ts.KeyStore.GetAccountKey("B")
+	require.NoError(t, err)
+	require.NotNil(t, kp)
+	require.True(t, store.Match(pk, kp))
+
+	// users and their keys move with the account
+	uc, err := ts.Store.ReadUserClaim("B", "a")
+	require.NoError(t, err)
+	require.NotNil(t, uc)
+	upk, err := ts.KeyStore.GetUserPublicKey("B", "a")
+	require.NoError(t, err)
+	require.Equal(t, uc.Subject, upk)
+	require.Equal(t, "B", GetConfig().Account)
+}
+
+func Test_RenameUser(t *testing.T) {
+	ts := NewTestStore(t, "rename user")
+	defer ts.Done(t)
+
+	ts.AddUser(t, "A", "a")
+	ts.AddUser(t, "A", "b")
+	pk, err := ts.KeyStore.GetUserPublicKey("A", "a")
+	require.NoError(t, err)
+
+	_, _, err = ExecuteCmd(createRenameCmd(nkeys.PrefixByteUser), "--user", pk[:8], "--to", "c")
+	require.NoError(t, err)
+
+	require.False(t, ts.Store.Has(store.Accounts, "A", store.Users, store.JwtName("a")))
+	uc, err := ts.Store.ReadUserClaim("A", "c")
+	require.NoError(t, err)
+	require.Equal(t, "c", uc.Name)
+	require.Equal(t, pk, uc.Subject)
+
+	kp, err := ts.KeyStore.GetUserKey("A", "c")
+	require.NoError(t, err)
+	require.NotNil(t, kp)
+	kp, err = ts.KeyStore.GetUserKey("A", "a")
+	require.NoError(t, err)
+	require.Nil(t, kp)
+
+	_, _, err = ExecuteCmd(createRenameCmd(nkeys.PrefixByteUser), "--user", "c", "--to", "b")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "user \"b\" already exists")
+}
+
+func Test_RenameClusterAndServer(t *testing.T) {
+	ts := NewTestStore(t, "rename cluster")
+	defer ts.Done(t)
+
+	ts.AddServer(t, "C", "s")
+	config := GetConfig()
+	ctx := config.ContextConfig
+	ctx.Cluster = "C"
+	require.NoError(t, config.AddContext("dev", ctx))
+	_, _, err := ExecuteCmd(createRenameCmd(nkeys.PrefixByteServer), "--cluster", "C", "--server", "s", "--to", "t")
+	require.NoError(t, err)
+	sc, err := ts.Store.ReadServerClaim("C", "t")
+	require.NoError(t, err)
+	require.Equal(t, "t", sc.Name)
+
+	_, _, err = ExecuteCmd(createRenameCmd(nkeys.PrefixByteCluster), "--cluster", "C", "--to", "D")
+	require.NoError(t, err)
+	cc, err := ts.Store.ReadClusterClaim("D")
+	require.NoError(t, err)
+	require.Equal(t, "D", cc.Name)
+	require.Equal(t, "D", config.Contexts["dev"].Cluster)
+	kp, err := ts.KeyStore.GetServerKey("D", "t")
+	require.NoError(t, err)
+	require.NotNil(t, kp)
+	require.True(t, store.Match(sc.Subject, kp))
+
+	// the servers embed the renamed cluster
+	sc, err = ts.Store.ReadServerClaim("D", "t")
+	require.NoError(t, err)
+	d, err := ts.Store.Read(store.Clusters, "D", store.JwtName("D"))
+	require.NoError(t, err)
+	require.Equal(t, string(d), sc.Cluster)
+}
+
+func Test_RenameRejectsInvalidNames(t *testing.T) {
+	ts := NewTestStore(t, "rename invalid")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	for _, n := range []string{"../x", "a/b", " x"} {
+		_, _, err := ExecuteCmd(createRenameCmd(nkeys.PrefixByteAccount), "--account", "A", "--to", n)
+		require.Error(t, err, n)
+		require.Contains(t, err.Error(), "invalid account name")
+	}
+	require.True(t, ts.Store.Has(store.Accounts, "A", store.JwtName("A")))
+}
+
+func Test_AccountPublicKeyPrefix(t *testing.T) {
+	ts := NewTestStore(t, "account prefix")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	ts.AddAccount(t, "B")
+	pk, err := ts.KeyStore.GetAccountPublicKey("B")
+	require.NoError(t, err)
+
+	out, _, err := ExecuteCmd(createDescribeAccountCmd(), "--account", pk[:store.MinKeyPrefix+2])
+	require.NoError(t, err)
+	require.Contains(t, out, pk)
+
+	// every account key starts with A
+	_, _, err = ExecuteCmd(createDescribeAccountCmd(), "--account", "A")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createEditAccount(), "--account", "AXXXXX", "--tag", "x")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "account \"AXXXXX\" not found")
+}
@@ -0,0 +1,404 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+func createRenewCmd() *cobra.Command {
+	var params RenewParams
+	cmd := &cobra.Command{
+		Use:   "renew",
+		Short: "Re-issue accounts, users, clusters, servers and activations that are about to expire",
+		Long: `Re-issue accounts, users, clusters, servers and activations that are about to expire
+
+JWTs that expire within the window specified by --within are re-issued. The new
+expiration is computed by --extend, or if not specified, by preserving the original
+lifetime of the JWT. Each JWT is signed by the key that issued it. Accounts in
+the store that import with a renewed activation are re-signed with the new one.`,
+		Example: `nsc renew --dry-run
+nsc renew --within 7d --extend 90d`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			if len(params.renewals) == 0 {
+				cmd.Println("Nothing expires within the specified window")
+				return nil
+			}
+			cmd.Println(params.Summary())
+			for _, s := range params.syncs {
+				s.Print(cmd)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.within, "within", "", "30d", "renew JWTs expiring within - #m(inutes), #h(ours), #d(ays), #w(eeks), #M(onths), #y(ears)")
+	cmd.Flags().StringVarP(&params.extend, "extend", "", "", "new expiration - yyyy-mm-dd, #m(inutes), #h(ours), #d(ays), #w(eeks), #M(onths), #y(ears), default is the original lifetime")
+	cmd.Flags().BoolVarP(&params.dryRun, "dry-run", "", false, "only print what would be renewed")
+
+	return cmd
+}
+
+func init() {
+	GetRootCmd().AddCommand(createRenewCmd())
+}
+
+// Renewal describes a JWT that should be re-issued
+type Renewal struct {
+	Kind       string
+	Name       string
+	Expires    int64
+	NewExpires int64
+	Renewed    bool
+	Error      string
+
+	claim      jwt.Claims
+	signer     nkeys.KeyPair
+	account    string
+	record     *store.ActivationRecord
+	token      string
+	activation *Renewal
+}
+
+type RenewParams struct {
+	cutoff   int64
+	dryRun   bool
+	extend   string
+	renewals []*Renewal
+	syncs    []*ServerSync
+	within   string
+}
+
+func (p *RenewParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RenewParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RenewParams) Load(ctx ActionCtx) error {
+	var err error
+	if p.within == "" || p.within == "0" {
+		ctx.CurrentCmd().SilenceUsage = false
+		return fmt.Errorf("a renewal window is required")
+	}
+	p.cutoff, err = ParseExpiry(p.within)
+	if err != nil {
+		return fmt.Errorf("within %q is invalid: %v", p.within, err)
+	}
+	if p.extend != "" {
+		if _, err := ParseExpiry(p.extend); err != nil {
+			return fmt.Errorf("extend %q is invalid: %v", p.extend, err)
+		}
+	}
+
+	sctx := ctx.StoreCtx()
+	s := sctx.Store
+	ks := sctx.KeyStore
+	opk, _ := ks.GetOperatorKey(sctx.Operator.Name)
+
+	accounts, err := s.ListSubContainers(store.Accounts)
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		ac, err := s.ReadAccountClaim(a)
+		if err != nil {
+			return err
+		}
+		akp, _ := ks.GetAccountKey(a)
+		if ac != nil {
+			p.add("account", a, ac, opk, akp)
+		}
+
+		users, err := s.ListEntries(store.Accounts, a, store.Users)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			uc, err := s.ReadUserClaim(a, u)
+			if err != nil {
+				return err
+			}
+			if uc != nil {
+				p.add("user", filepath.Join(a, u), uc, akp)
+			}
+		}
+
+		activations, err := s.ListActivations(a)
+		if err != nil {
+			return err
+		}
+		for _, r := range activations {
+			if r.IsRevoked() || r.RenewedBy != "" || !p.expiring(r.Expires) {
+				continue
+			}
+			ac, err := jwt.DecodeActivationClaims(r.Token)
+			if err != nil {
+				return fmt.Errorf("error decoding activation %q: %v", r.ID, err)
+			}
+			if v := p.add("activation", fmt.Sprintf("%s/%s", a, r.ID), ac, akp); v != nil {
+				v.account = a
+				v.record = r
+				if err := p.addImporter(ctx, v, opk); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	clusters, err := s.ListSubContainers(store.Clusters)
+	if err != nil {
+		return err
+	}
+	for _, c := range clusters {
+		cc, err := s.ReadClusterClaim(c)
+		if err != nil {
+			return err
+		}
+		ckp, _ := ks.GetClusterKey(c)
+		if cc != nil {
+			p.add("cluster", c, cc, opk, ckp)
+		}
+
+		servers, err := s.ListEntries(store.Clusters, c, store.Servers)
+		if err != nil {
+			return err
+		}
+		for _, n := range servers {
+			sc, err := s.ReadServerClaim(c, n)
+			if err != nil {
+				return err
+			}
+			if sc != nil {
+				p.add("server", filepath.Join(c, n), sc, ckp)
+			}
+		}
+	}
+	return nil
+}
+
+// addImporter records the update of the account in the store that imports with
+// the renewed activation, so it embeds the new token
+func (p *RenewParams) addImporter(ctx ActionCtx, activation *Renewal, opk nkeys.KeyPair) error {
+	s := ctx.StoreCtx().Store
+	name, err := s.FindAccount(activation.record.Target)
+	if err != nil || name == "" {
+		return err
+	}
+	ac, err := s.ReadAccountClaim(name)
+	if err != nil {
+		return err
+	}
+	if ac == nil || !hasImportToken(ac, activation.record.Token) {
+		return nil
+	}
+	r := &Renewal{Kind: "import", Name: fmt.Sprintf("%s imports %s", name, activation.Name),
+		Expires: activation.Expires, NewExpires: activation.NewExpires, account: name, activation: activation}
+	akp, _ := ctx.StoreCtx().KeyStore.GetAccountKey(name)
+	for _, kp := range []nkeys.KeyPair{opk, akp} {
+		if kp != nil && store.Match(ac.Issuer, kp) {
+			r.signer = kp
+			break
+		}
+	}
+	if r.signer == nil {
+		r.Error = fmt.Sprintf("no key for issuer %s", ac.Issuer)
+	}
+	p.renewals = append(p.renewals, r)
+	return nil
+}
+
+func hasImportToken(ac *jwt.AccountClaims, token string) bool {
+	for _, im := range ac.Imports {
+		if im.Token == token {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RenewParams) expiring(exp int64) bool {
+	return exp > 0 && exp <= p.cutoff
+}
+
+// add records a renewal for the claim if it expires within the window. The signer
+// is the first of the candidate keys that matches the issuer of the claim. The
+// claim can't be nil, callers check their concrete pointers as a nil pointer in
+// a jwt.Claims is not a nil interface.
+func (p *RenewParams) add(kind string, name string, c jwt.Claims, candidates ...nkeys.KeyPair) *Renewal {
+	cd := c.Claims()
+	if !p.expiring(cd.Expires) {
+		return nil
+	}
+	r := &Renewal{Kind: kind, Name: name, Expires: cd.Expires, claim: c}
+	r.NewExpires = p.newExpiry(cd)
+	for _, kp := range candidates {
+		if kp != nil && store.Match(cd.Issuer, kp) {
+			r.signer = kp
+			break
+		}
+	}
+	if r.signer == nil {
+		r.Error = fmt.Sprintf("no key for issuer %s", cd.Issuer)
+	}
+	p.renewals = append(p.renewals, r)
+	return r
+}
+
+func (p *RenewParams) newExpiry(cd *jwt.ClaimsData) int64 {
+	if p.extend != "" {
+		v, _ := ParseExpiry(p.extend)
+		return v
+	}
+	start := cd.IssuedAt
+	if cd.NotBefore > start {
+		start = cd.NotBefore
+	}
+	return time.Now().Unix() + (cd.Expires - start)
+}
+
+func (p *RenewParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RenewParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *RenewParams) Run(ctx ActionCtx) error {
+	if p.dryRun {
+		return nil
+	}
+	s := ctx.StoreCtx().Store
+	for _, r := range p.renewals {
+		if r.signer == nil || r.activation != nil {
+			continue
+		}
+		r.claim.Claims().Expires = r.NewExpires
+		token, err := r.claim.Encode(r.signer)
+		if err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		if r.record != nil {
+			if err := p.storeActivation(s, r, token); err != nil {
+				r.Error = err.Error()
+				continue
+			}
+		} else if err := s.StoreClaim([]byte(token)); err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		r.token = token
+		r.Renewed = true
+	}
+
+	// importers are updated last, after the renewal of their own JWT
+	for _, r := range p.renewals {
+		if r.signer == nil || r.activation == nil {
+			continue
+		}
+		if err := p.updateImporter(s, r); err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		r.Renewed = true
+	}
+
+	// servers embed the cluster JWT
+	for _, r := range p.renewals {
+		if r.Kind != "cluster" || !r.Renewed {
+			continue
+		}
+		sync, err := SyncServers(ctx, r.Name, r.token)
+		if err != nil {
+			return err
+		}
+		p.syncs = append(p.syncs, sync)
+	}
+	return nil
+}
+
+// updateImporter replaces the renewed activation in the imports of the account
+func (p *RenewParams) updateImporter(s *store.Store, r *Renewal) error {
+	if !r.activation.Renewed {
+		return fmt.Errorf("activation %s was not renewed", r.activation.Name)
+	}
+	ac, err := s.ReadAccountClaim(r.account)
+	if err != nil {
+		return err
+	}
+	if ac == nil {
+		return fmt.Errorf("account %q not found", r.account)
+	}
+	for _, im := range ac.Imports {
+		if im.Token == r.activation.record.Token {
+			im.Token = r.activation.token
+		}
+	}
+	token, err := ac.Encode(r.signer)
+	if err != nil {
+		return err
+	}
+	return s.StoreClaim([]byte(token))
+}
+
+func (p *RenewParams) storeActivation(s *store.Store, r *Renewal, token string) error {
+	nr, err := store.NewActivationRecord(token)
+	if err != nil {
+		return err
+	}
+	if err := s.StoreActivation(r.account, nr); err != nil {
+		return err
+	}
+	r.record.RenewedBy = nr.ID
+	return s.StoreActivation(r.account, r.record)
+}
+
+func (p *RenewParams) Summary() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	if p.dryRun {
+		table.AddTitle("Renewals (dry-run)")
+	} else {
+		table.AddTitle("Renewals")
+	}
+	table.AddHeaders("Kind", "Name", "Expires", "New Expiry", "Status")
+	for _, r := range p.renewals {
+		status := "Renewed"
+		switch {
+		case r.Error != "":
+			status = fmt.Sprintf("Skipped - %s", r.Error)
+		case p.dryRun:
+			status = "Would renew"
+		}
+		table.AddRow(r.Kind, r.Name, HumanizedDate(r.Expires), UnixToDate(r.NewExpires), status)
+	}
+	return table.Render()
+}
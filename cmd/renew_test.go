@@ -0,0 +1,187 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RenewNothingToDo(t *testing.T) {
+	ts := NewTestStore(t, "renew")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	ts.AddUser(t, "A", "U")
+
+	_, stderr, err := ExecuteCmd(createRenewCmd())
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Nothing expires within the specified window")
+}
+
+func Test_RenewDryRun(t *testing.T) {
+	ts := NewTestStore(t, "renew")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(CreateAddAccountCmd(), "--name", "A", "--expiry", "2d")
+	require.NoError(t, err)
+	ac, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+
+	_, stderr, err := ExecuteCmd(createRenewCmd(), "--dry-run")
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "Renewals (dry-run)")
+	require.Contains(t, stderr, "account A")
+	require.Contains(t, stderr, "Would renew")
+
+	ac2, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Equal(t, ac.ID, ac2.ID)
+}
+
+func Test_Renew(t *testing.T) {
+	ts := NewTestStore(t, "renew")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(CreateAddAccountCmd(), "--name", "A", "--expiry", "2d")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(CreateAddUserCmd(), "--account", "A", "--name", "U", "--expiry", "1d")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(CreateAddUserCmd(), "--account", "A", "--name", "V", "--expiry", "1y")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createAddExportCmd(), "--account", "A", "--subject", "foo", "--private")
+	require.NoError(t, err)
+	_, pub, _ := CreateAccountKey(t)
+	_, _, err = ExecuteCmd(createGenerateActivationCmd(), "--account", "A", "--target-account", pub, "--expiry", "3d")
+	require.NoError(t, err)
+
+	v, err := ts.Store.ReadUserClaim("A", "V")
+	require.NoError(t, err)
+
+	_, stderr, err := ExecuteCmd(createRenewCmd(), "--extend", "90d")
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "account A")
+	require.Contains(t, stderr, "user A/U")
+	require.Contains(t, stderr, "activation A/")
+	require.NotContains(t, stderr, "user A/V")
+
+	limit := time.Now().AddDate(0, 0, 80).Unix()
+
+	ac, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.True(t, ac.Expires > limit)
+
+	uc, err := ts.Store.ReadUserClaim("A", "U")
+	require.NoError(t, err)
+	require.True(t, uc.Expires > limit)
+
+	v2, err := ts.Store.ReadUserClaim("A", "V")
+	require.NoError(t, err)
+	require.Equal(t, v.ID, v2.ID)
+
+	activations, err := ts.Store.ListActivations("A")
+	require.NoError(t, err)
+	require.Len(t, activations, 2)
+	require.True(t, activations[0].Expires < limit)
+	require.Equal(t, activations[1].ID, activations[0].RenewedBy)
+	require.True(t, activations[1].Expires > limit)
+
+	_, stderr, err = ExecuteCmd(createRenewCmd())
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Nothing expires within the specified window")
+}
+
+func Test_RenewUpdatesImporters(t *testing.T) {
+	ts := NewTestStore(t, "renew")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo", false)
+	ts.AddAccount(t, "B")
+	tpub, err := ts.KeyStore.GetAccountPublicKey("B")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createGenerateActivationCmd(), "--account", "A", "--target-account", tpub, "--expiry", "3d")
+	require.NoError(t, err)
+	activations, err := ts.Store.ListActivations("A")
+	require.NoError(t, err)
+	require.Len(t, activations, 1)
+	fp := filepath.Join(ts.Dir, "token")
+	require.NoError(t, ioutil.WriteFile(fp, []byte(activations[0].Token), 0600))
+	_, _, err = ExecuteCmd(createAddImportCmd(), "--account", "B", "--token", fp)
+	require.NoError(t, err)
+
+	_, stderr, err := ExecuteCmd(createRenewCmd(), "--extend", "90d")
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "import B imports A/")
+
+	activations, err = ts.Store.ListActivations("A")
+	require.NoError(t, err)
+	require.Len(t, activations, 2)
+	renewed := activations[1]
+	require.Equal(t, renewed.ID, activations[0].RenewedBy)
+
+	bc, err := ts.Store.ReadAccountClaim("B")
+	require.NoError(t, err)
+	require.Len(t, bc.Imports, 1)
+	require.Equal(t, renewed.Token, bc.Imports[0].Token)
+}
+
+func Test_RenewPreservesLifetime(t *testing.T) {
+	ts := NewTestStore(t, "renew")
+	defer ts.Done(t)
+
+	ts.AddCluster(t, "C")
+	_, _, err := ExecuteCmd(createAddServerCmd(), "--cluster", "C", "--name", "S", "--expiry", "2w")
+	require.NoError(t, err)
+	sc, err := ts.Store.ReadServerClaim("C", "S")
+	require.NoError(t, err)
+	lifetime := sc.Expires - sc.IssuedAt
+
+	_, _, err = ExecuteCmd(createRenewCmd())
+	require.NoError(t, err)
+
+	sc, err = ts.Store.ReadServerClaim("C", "S")
+	require.NoError(t, err)
+	require.InDelta(t, lifetime, sc.Expires-sc.IssuedAt, 5)
+	require.NotEmpty(t, sc.Cluster)
+}
+
+func Test_RenewClusterSyncsServers(t *testing.T) {
+	ts := NewTestStore(t, "renew")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(createAddClusterCmd(), "--name", "C", "--expiry", "2d")
+	require.NoError(t, err)
+	ts.AddServer(t, "C", "S")
+
+	_, stderr, err := ExecuteCmd(createRenewCmd(), "--extend", "90d")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Re-issued server \"S\" with the new cluster JWT")
+
+	d, err := ts.Store.Read(store.Clusters, "C", store.JwtName("C"))
+	require.NoError(t, err)
+	sc, err := ts.Store.ReadServerClaim("C", "S")
+	require.NoError(t, err)
+	require.Equal(t, string(d), sc.Cluster)
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+const (
+	ExpiredGroup = "expired"
+	Next7Group   = "next 7 days"
+	Next30Group  = "next 30 days"
+)
+
+func createReportExpirationsCmd() *cobra.Command {
+	var params ReportExpirationsParams
+	cmd := &cobra.Command{
+		Use:   "expirations",
+		Short: "Report JWTs that have expired or expire within 30 days",
+		Long: `Report JWTs that have expired or expire within 30 days
+
+The report includes the operator, accounts, users, clusters, servers and the activation
+tokens embedded in imports. The command exits with an error if any JWT has expired.`,
+		Example: `nsc report expirations
+nsc report expirations --json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			if n := params.Count(ExpiredGroup); n > 0 {
+				return fmt.Errorf("%d JWT(s) have expired", n)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&params.json, "json", "", false, "output the report as json")
+	cmd.Flags().StringVarP(&params.outputFile, "output-file", "o", "--", "output file, '--' is stdout")
+
+	return cmd
+}
+
+func init() {
+	reportCmd.AddCommand(createReportExpirationsCmd())
+}
+
+// Expiration describes when a JWT in the store expires
+type Expiration struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+	Expires int64  `json:"expires"`
+	Group   string `json:"group"`
+}
+
+type ReportExpirationsParams struct {
+	entries    []Expiration
+	json       bool
+	outputFile string
+}
+
+func (p *ReportExpirationsParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ReportExpirationsParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ReportExpirationsParams) Load(ctx ActionCtx) error {
+	all, err := CollectExpirations(ctx.StoreCtx().Store)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, v := range all {
+		switch {
+		case v.Expires < now.Unix():
+			v.Group = ExpiredGroup
+		case v.Expires < now.AddDate(0, 0, 7).Unix():
+			v.Group = Next7Group
+		case v.Expires < now.AddDate(0, 0, 30).Unix():
+			v.Group = Next30Group
+		default:
+			continue
+		}
+		p.entries = append(p.entries, v)
+	}
+	return nil
+}
+
+func (p *ReportExpirationsParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ReportExpirationsParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ReportExpirationsParams) Run(ctx ActionCtx) error {
+	if p.json {
+		if p.entries == nil {
+			p.entries = []Expiration{}
+		}
+		d, err := json.MarshalIndent(p.entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		return Write(p.outputFile, append(d, '\n'))
+	}
+	if len(p.entries) == 0 {
+		return Write(p.outputFile, []byte("No JWTs expire within the next 30 days\n"))
+	}
+	return Write(p.outputFile, []byte(p.Describe()))
+}
+
+func (p *ReportExpirationsParams) Count(group string) int {
+	c := 0
+	for _, v := range p.entries {
+		if v.Group == group {
+			c++
+		}
+	}
+	return c
+}
+
+func (p *ReportExpirationsParams) Describe() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle("Expirations")
+	table.AddHeaders("Kind", "Name", "Subject", "Expires", "")
+	first := true
+	for _, g := range []string{ExpiredGroup, Next7Group, Next30Group} {
+		if p.Count(g) == 0 {
+			continue
+		}
+		if !first {
+			table.AddSeparator()
+		}
+		first = false
+		table.AddRow(strings.Title(g), "", "", "", "")
+		for _, v := range p.entries {
+			if v.Group == g {
+				table.AddRow(v.Kind, v.Name, v.Subject, UnixToDate(v.Expires), HumanizedDate(v.Expires))
+			}
+		}
+	}
+	return table.Render()
+}
+
+// CollectExpirations returns all the JWTs in the store that have an expiration,
+// including activations embedded in imports, sorted by expiration
+func CollectExpirations(s *store.Store) ([]Expiration, error) {
+	var entries []Expiration
+	add := func(kind string, name string, cd *jwt.ClaimsData) {
+		if cd.Expires > 0 {
+			entries = append(entries, Expiration{Kind: kind, Name: name, Subject: cd.Subject, Issuer: cd.Issuer, Expires: cd.Expires})
+		}
+	}
+
+	oc, err := s.LoadRootClaim()
+	if err != nil {
+		return nil, err
+	}
+	if oc != nil {
+		add("operator", oc.Name, &oc.ClaimsData)
+	}
+
+	accounts, err := s.ListSubContainers(store.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range accounts {
+		ac, err := s.ReadAccountClaim(a)
+		if err != nil {
+			return nil, err
+		}
+		if ac == nil {
+			continue
+		}
+		add("account", a, &ac.ClaimsData)
+		for _, im := range ac.Imports {
+			d := NewImportDescriber(*im)
+			if im.Token == "" || d.IsRemoteImport() {
+				continue
+			}
+			act, err := jwt.DecodeActivationClaims(im.Token)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding activation for import %q in account %q: %v", im.Subject, a, err)
+			}
+			add("activation", fmt.Sprintf("%s import %s", a, im.Subject), &act.ClaimsData)
+		}
+
+		users, err := s.ListEntries(store.Accounts, a, store.Users)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			uc, err := s.ReadUserClaim(a, u)
+			if err != nil {
+				return nil, err
+			}
+			if uc == nil {
+				continue
+			}
+			add("user", filepath.Join(a, u), &uc.ClaimsData)
+		}
+	}
+
+	clusters, err := s.ListSubContainers(store.Clusters)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clusters {
+		cc, err := s.ReadClusterClaim(c)
+		if err != nil {
+			return nil, err
+		}
+		if cc == nil {
+			continue
+		}
+		add("cluster", c, &cc.ClaimsData)
+
+		servers, err := s.ListEntries(store.Clusters, c, store.Servers)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range servers {
+			sc, err := s.ReadServerClaim(c, n)
+			if err != nil {
+				return nil, err
+			}
+			if sc == nil {
+				continue
+			}
+			add("server", filepath.Join(c, n), &sc.ClaimsData)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Expires < entries[j].Expires
+	})
+	return entries, nil
+}
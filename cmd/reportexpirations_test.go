@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReportExpirationsEmpty(t *testing.T) {
+	ts := NewTestStore(t, "report")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	stdout, _, err := ExecuteCmd(createReportExpirationsCmd())
+	require.NoError(t, err)
+	require.Contains(t, stdout, "No JWTs expire within the next 30 days")
+}
+
+func Test_ReportExpirations(t *testing.T) {
+	ts := NewTestStore(t, "report")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(CreateAddAccountCmd(), "--name", "A", "--expiry", "20d")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(CreateAddUserCmd(), "--account", "A", "--name", "U", "--expiry", "2d")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(CreateAddUserCmd(), "--account", "A", "--name", "V", "--expiry", "1y")
+	require.NoError(t, err)
+	ts.AddExport(t, "A", jwt.Stream, "foo", false)
+	ts.AddAccount(t, "B")
+	tpub, err := ts.KeyStore.GetAccountPublicKey("B")
+	require.NoError(t, err)
+	_, _, err = ExecuteCmd(createGenerateActivationCmd(), "--account", "A", "--target-account", tpub, "--expiry", "3d")
+	require.NoError(t, err)
+	activations, err := ts.Store.ListActivations("A")
+	require.NoError(t, err)
+	require.Len(t, activations, 1)
+	require.NoError(t, ioutil.WriteFile(ts.Dir+"/token", []byte(activations[0].Token), 0600))
+	_, _, err = ExecuteCmd(createAddImportCmd(), "--account", "B", "--token", ts.Dir+"/token")
+	require.NoError(t, err)
+
+	stdout, _, err := ExecuteCmd(createReportExpirationsCmd())
+	require.NoError(t, err)
+	stdout = StripTableDecorations(stdout)
+	require.Contains(t, stdout, "Next 7 Days user A/U")
+	require.Contains(t, stdout, "activation B import foo")
+	require.Contains(t, stdout, "Next 30 Days account A")
+	require.NotContains(t, stdout, "A/V")
+
+	stdout, _, err = ExecuteCmd(createReportExpirationsCmd(), "--json")
+	require.NoError(t, err)
+	var entries []Expiration
+	require.NoError(t, json.Unmarshal([]byte(stdout), &entries))
+	require.Len(t, entries, 3)
+	require.Equal(t, "user", entries[0].Kind)
+	require.Equal(t, Next7Group, entries[0].Group)
+	require.Equal(t, "activation", entries[1].Kind)
+	require.Equal(t, "account", entries[2].Kind)
+	require.Equal(t, Next30Group, entries[2].Group)
+}
+
+func Test_ReportExpirationsFailsOnExpired(t *testing.T) {
+	ts := NewTestStore(t, "report")
+	defer ts.Done(t)
+
+	ts.AddUser(t, "A", "U")
+	uc, err := ts.Store.ReadUserClaim("A", "U")
+	require.NoError(t, err)
+	uc.Expires = time.Now().Add(-time.Hour).Unix()
+	akp, err := ts.KeyStore.GetAccountKey("A")
+	require.NoError(t, err)
+	token, err := uc.Encode(akp)
+	require.NoError(t, err)
+	require.NoError(t, ts.Store.StoreClaim([]byte(token)))
+
+	stdout, _, err := ExecuteCmd(createReportExpirationsCmd())
+	require.Error(t, err)
+	require.Equal(t, "1 JWT(s) have expired", err.Error())
+	require.Contains(t, StripTableDecorations(stdout), "Expired user A/U")
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cli"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+)
+
+// RevokedActivationTagPrefix is prepended to the JTI of revoked activations
+// in the tags of the exporting account
+const RevokedActivationTagPrefix = "revoked:"
+
+func createRevokeActivationCmd() *cobra.Command {
+	var params RevokeActivationParams
+	cmd := &cobra.Command{
+		Use:   "activation",
+		Short: "Revoke an activation issued by an account",
+		Example: `nsc revoke activation -i
+nsc revoke activation --account A --jti <jti>`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			cmd.Printf("Success! - revoked activation %q for account %q\n", params.record.ID, params.record.Target)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&params.jti, "jti", "", "", "the JTI of the activation to revoke")
+	params.AccountContextParams.BindFlags(cmd)
+
+	return cmd
+}
+
+func init() {
+	revokeCmd.AddCommand(createRevokeActivationCmd())
+}
+
+type RevokeActivationParams struct {
+	AccountContextParams
+	SignerParams
+	activations []*store.ActivationRecord
+	claim       *jwt.AccountClaims
+	jti         string
+	record      *store.ActivationRecord
+}
+
+func RevokedActivationTag(jti string) string {
+	return strings.ToLower(RevokedActivationTagPrefix + jti)
+}
+
+func (p *RevokeActivationParams) SetDefaults(ctx ActionCtx) error {
+	p.AccountContextParams.SetDefaults(ctx)
+	p.SignerParams.SetDefaults(nkeys.PrefixByteOperator, true, ctx)
+	return nil
+}
+
+func (p *RevokeActivationParams) PreInteractive(ctx ActionCtx) error {
+	return p.AccountContextParams.Edit(ctx)
+}
+
+func (p *RevokeActivationParams) Load(ctx ActionCtx) error {
+	var err error
+
+	if err = p.AccountContextParams.Validate(ctx); err != nil {
+		return err
+	}
+
+	p.claim, err = ctx.StoreCtx().Store.ReadAccountClaim(p.AccountContextParams.Name)
+	if err != nil {
+		return err
+	}
+	if p.claim == nil {
+		return fmt.Errorf("account %q is not defined in the current context", p.AccountContextParams.Name)
+	}
+
+	all, err := ctx.StoreCtx().Store.ListActivations(p.AccountContextParams.Name)
+	if err != nil {
+		return err
+	}
+	for _, v := range all {
+		if !v.IsRevoked() {
+			p.activations = append(p.activations, v)
+		}
+	}
+	if len(p.activations) == 0 {
+		return fmt.Errorf("account %q doesn't have activations that can be revoked", p.AccountContextParams.Name)
+	}
+	return nil
+}
+
+func (p *RevokeActivationParams) PostInteractive(ctx ActionCtx) error {
+	if p.jti == "" {
+		var choices []string
+		for _, v := range p.activations {
+			choices = append(choices, fmt.Sprintf("%s - [%s] %s for %s", v.ID, v.Type, v.Subject, v.Target))
+		}
+		i, err := cli.PromptChoices("select activation to revoke", choices)
+		if err != nil {
+			return err
+		}
+		p.jti = p.activations[i].ID
+	}
+	return p.SignerParams.Edit(ctx)
+}
+
+func (p *RevokeActivationParams) Validate(ctx ActionCtx) error {
+	var err error
+	if p.jti == "" {
+		ctx.CurrentCmd().SilenceUsage = false
+		return errors.New("jti is required")
+	}
+
+	p.record, err = ctx.StoreCtx().Store.ReadActivation(p.AccountContextParams.Name, p.jti)
+	if err != nil {
+		return err
+	}
+	if p.record == nil {
+		return fmt.Errorf("activation %q was not issued by account %q", p.jti, p.AccountContextParams.Name)
+	}
+	if p.record.IsRevoked() {
+		return fmt.Errorf("activation %q is already revoked", p.jti)
+	}
+
+	if err = p.SignerParams.Resolve(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *RevokeActivationParams) Run(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+
+	p.claim.Tags.Add(RevokedActivationTag(p.record.ID))
+	token, err := p.claim.Encode(p.signerKP)
+	if err != nil {
+		return err
+	}
+	if err := s.StoreClaim([]byte(token)); err != nil {
+		return err
+	}
+
+	p.record.Revoked = time.Now().Unix()
+	return s.StoreActivation(p.AccountContextParams.Name, p.record)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/nats-io/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RevokeActivation(t *testing.T) {
+	ts := NewTestStore(t, "revoke activation")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo.>", false)
+	ts.AddAccount(t, "B")
+	token := ts.GenerateActivation(t, "A", "foo.>", "B")
+	ac, err := jwt.DecodeActivationClaims(token)
+	require.NoError(t, err)
+
+	tests := CmdTests{
+		{createRevokeActivationCmd(), []string{"revoke", "activation", "--account", "B"}, nil, []string{"account \"B\" doesn't have activations that can be revoked"}, true},
+		{createRevokeActivationCmd(), []string{"revoke", "activation", "--account", "A"}, nil, []string{"jti is required"}, true},
+		{createRevokeActivationCmd(), []string{"revoke", "activation", "--account", "A", "--jti", "X"}, nil, []string{"activation \"X\" was not issued by account \"A\""}, true},
+		{createRevokeActivationCmd(), []string{"revoke", "activation", "--account", "A", "--jti", "../../B/B"}, nil, []string{"is not a valid jti"}, true},
+		{createRevokeActivationCmd(), []string{"revoke", "activation", "--account", "A", "--jti", ac.ID}, nil, []string{"revoked activation"}, false},
+		{createRevokeActivationCmd(), []string{"revoke", "activation", "--account", "A", "--jti", ac.ID}, nil, []string{"doesn't have activations that can be revoked"}, true},
+	}
+	tests.Run(t, "root", "revoke")
+
+	r, err := ts.Store.ReadActivation("A", ac.ID)
+	require.NoError(t, err)
+	require.True(t, r.IsRevoked())
+
+	claim, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.True(t, claim.Tags.Contains(RevokedActivationTag(ac.ID)))
+}
+
+func Test_RevokeActivationInteractive(t *testing.T) {
+	ts := NewTestStore(t, "revoke activation")
+	defer ts.Done(t)
+
+	ts.AddExport(t, "A", jwt.Stream, "foo.>", false)
+	ts.AddAccount(t, "B")
+	token := ts.GenerateActivation(t, "A", "foo.>", "B")
+	ac, err := jwt.DecodeActivationClaims(token)
+	require.NoError(t, err)
+
+	cmd := createRevokeActivationCmd()
+	HoistRootFlags(cmd)
+	_, _, err = ExecuteInteractiveCmd(cmd, []interface{}{0, 0}, "-i")
+	require.NoError(t, err)
+
+	r, err := ts.Store.ReadActivation("A", ac.ID)
+	require.NoError(t, err)
+	require.True(t, r.IsRevoked())
+}
@@ -34,8 +34,11 @@ const TestEnv = "NSC_TEST"
 
 var KeyPathFlag string
 var InteractiveFlag bool
+var ContextFlag string
 
 var cfgFile string
+var configErr error
+var configViper *viper.Viper
 var ngsStore *store.Store
 var interceptorFn InterceptorFn
 
@@ -101,6 +104,9 @@ func SetInterceptor(fn InterceptorFn) {
 }
 
 func RunInterceptor(ctx ActionCtx, params interface{}) error {
+	if err := AuditAction(ctx); err != nil {
+		return err
+	}
 	if interceptorFn != nil {
 		return interceptorFn(ctx, params)
 	}
@@ -117,7 +123,32 @@ var rootCmd = &cobra.Command{
 	Long: `The ncs tool allows you to create NATS account, users and manage their permissions.
 
 The nsc cli creates accounts, users, and JWT tokens that provide access
-to your users and services.`,
+to your users and services.
+
+The store, operator, account, cluster and private key settings are resolved
+in the following order, later sources overriding earlier ones:
+  - the current context saved in the tool configuration
+  - the store containing the working directory
+  - the config file specified by --config, or $HOME/.nsc.(json|yaml|toml)
+  - the NSC_STORE, NSC_OPERATOR, NSC_ACCOUNT, NSC_CLUSTER and
+    NSC_PRIVATE_KEY environment variables
+  - the context specified by --context
+  - the flags of the command
+'env' shows the effective values and where they came from.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configErr != nil {
+			return configErr
+		}
+		return ApplyContextFlag()
+	},
+}
+
+// ApplyContextFlag switches to the context specified by --context for this invocation
+func ApplyContextFlag() error {
+	if ContextFlag == "" {
+		return nil
+	}
+	return GetConfig().OverrideContext(ContextFlag)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -139,15 +170,24 @@ func init() {
 func HoistRootFlags(cmd *cobra.Command) *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&KeyPathFlag, "private-key", "K", "", "private key")
 	cmd.PersistentFlags().BoolVarP(&InteractiveFlag, "interactive", "i", false, "ask questions for various settings")
+	cmd.PersistentFlags().StringVarP(&ContextFlag, "context", "", "", "named context to use for this command")
+	cmd.PersistentFlags().StringVarP(&cfgFile, "config", "", "", "config file providing store, operator, account, cluster and private_key settings")
 
 	return cmd
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	configErr = nil
+	v := viper.New()
+	configViper = v
 	if cfgFile != "" {
 		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
+		if _, err := os.Stat(cfgFile); os.IsNotExist(err) {
+			configErr = fmt.Errorf("config file %q doesn't exist", cfgFile)
+			return
+		}
+		v.SetConfigFile(cfgFile)
 	} else {
 		// Find home directory.
 		home, err := homedir.Dir()
@@ -157,14 +197,17 @@ func initConfig() {
 		}
 
 		// Search config in home directory with name ".nsc" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigName(".nsc")
+		v.AddConfigPath(home)
+		v.SetConfigName(".nsc")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	v.SetEnvPrefix(SettingEnvPrefix)
+	v.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	// If a config file is found, read it in. A file requested with --config must exist.
+	if err := v.ReadInConfig(); err != nil && cfgFile != "" {
+		configErr = fmt.Errorf("error reading config file %q: %v", cfgFile, err)
+		return
 	}
+	configErr = GetConfig().ApplySettings(v)
 }
@@ -0,0 +1,365 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+)
+
+const (
+	jwtServerAccountsPath = "/jwt/v1/accounts/"
+	jwtServerOperatorPath = "/jwt/v1/operator"
+	// largest account JWT accepted by the server
+	jwtServerMaxUpload = 1024 * 1024
+	// time clients have to send a request, and the server to answer it
+	jwtServerReadTimeout  = 30 * time.Second
+	jwtServerWriteTimeout = 30 * time.Second
+	jwtServerIdleTimeout  = 2 * time.Minute
+	// time in flight requests have to complete on shutdown
+	jwtServerShutdownTimeout = 5 * time.Second
+)
+
+func createServeCmd() *cobra.Command {
+	var params ServeParams
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve account and operator JWTs over HTTP",
+		Long: `Serve account and operator JWTs over HTTP
+
+The server resolves account JWTs by public key at ` + jwtServerAccountsPath + `<pubkey>
+and the operator JWT at ` + jwtServerOperatorPath + `. Use the printed urls as
+the --account-url-template and --operator-url-template of a cluster.
+
+Account JWTs POSTed to ` + jwtServerAccountsPath + `<pubkey> are verified against the
+operator and saved in the store, unless --read-only is specified.`,
+		Example: `nsc serve
+nsc serve --listen 0.0.0.0:9090 --max-age 5m --read-only`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunAction(cmd, args, &params)
+		},
+	}
+	cmd.Flags().StringVarP(&params.listen, "listen", "", "localhost:9090", "host:port the server listens on")
+	cmd.Flags().DurationVarP(&params.maxAge, "max-age", "", time.Minute, "duration clients may cache served JWTs")
+	cmd.Flags().BoolVarP(&params.readOnly, "read-only", "", false, "reject account JWT updates")
+
+	return cmd
+}
+
+func init() {
+	GetRootCmd().AddCommand(createServeCmd())
+}
+
+type ServeParams struct {
+	listen   string
+	maxAge   time.Duration
+	readOnly bool
+	server   *JwtServer
+}
+
+// LocksStore opts out of holding the store lock while serving, writes lock it
+func (p *ServeParams) LocksStore() {}
+
+func (p *ServeParams) SetDefaults(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ServeParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ServeParams) Load(ctx ActionCtx) error {
+	p.server = NewJwtServer(ctx.StoreCtx().Store)
+	p.server.MaxAge = p.maxAge
+	p.server.ReadOnly = p.readOnly
+	return nil
+}
+
+func (p *ServeParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *ServeParams) Validate(ctx ActionCtx) error {
+	if p.maxAge < 0 {
+		return fmt.Errorf("max-age cannot be negative")
+	}
+	if _, err := p.server.operator(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *ServeParams) Run(ctx ActionCtx) error {
+	l, err := net.Listen("tcp", p.listen)
+	if err != nil {
+		return fmt.Errorf("error listening on %q: %v", p.listen, err)
+	}
+	base := fmt.Sprintf("http://%s", l.Addr().String())
+	cmd := ctx.CurrentCmd()
+	cmd.Printf("Serving JWTs for operator %q\n", ctx.StoreCtx().Operator.Name)
+	cmd.Printf("  account url template:  %s%s\n", base, jwtServerAccountsPath)
+	cmd.Printf("  operator url template: %s%s\n", base, jwtServerOperatorPath)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	return p.serve(ctx, l, stop)
+}
+
+// serve handles requests on the listener until a value is received on stop
+func (p *ServeParams) serve(ctx ActionCtx, l net.Listener, stop <-chan os.Signal) error {
+	srv := &http.Server{
+		Handler:           p.server,
+		ReadHeaderTimeout: jwtServerReadTimeout,
+		ReadTimeout:       jwtServerReadTimeout,
+		WriteTimeout:      jwtServerWriteTimeout,
+		IdleTimeout:       jwtServerIdleTimeout,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(l)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-stop:
+		ctx.CurrentCmd().Println("Shutting down")
+		sctx, cancel := context.WithTimeout(context.Background(), jwtServerShutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(sctx)
+	}
+}
+
+// JwtServer is an http.Handler that serves the account and operator JWTs in a store
+type JwtServer struct {
+	sync.Mutex
+	Store    *store.Store
+	MaxAge   time.Duration
+	ReadOnly bool
+}
+
+func NewJwtServer(s *store.Store) *JwtServer {
+	return &JwtServer{Store: s, MaxAge: time.Minute}
+}
+
+func (j *JwtServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == jwtServerOperatorPath:
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			j.methodNotAllowed(w, http.MethodGet, http.MethodHead)
+			return
+		}
+		j.getOperator(w, r)
+	case strings.HasPrefix(r.URL.Path, jwtServerAccountsPath):
+		pubkey := strings.TrimPrefix(r.URL.Path, jwtServerAccountsPath)
+		if pubkey == "" || strings.Contains(pubkey, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			j.getAccount(w, r, pubkey)
+		case http.MethodPost:
+			j.postAccount(w, r, pubkey)
+		default:
+			j.methodNotAllowed(w, http.MethodGet, http.MethodHead, http.MethodPost)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (j *JwtServer) methodNotAllowed(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+func (j *JwtServer) operator() (*jwt.OperatorClaims, error) {
+	fn := store.JwtName(j.Store.GetName())
+	if !j.Store.Has(fn) {
+		return nil, fmt.Errorf("store doesn't have an operator jwt")
+	}
+	d, err := j.Store.Read(fn)
+	if err != nil {
+		return nil, err
+	}
+	oc, err := jwt.DecodeOperatorClaims(string(d))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding operator jwt: %v", err)
+	}
+	return oc, nil
+}
+
+func (j *JwtServer) getOperator(w http.ResponseWriter, r *http.Request) {
+	fn := store.JwtName(j.Store.GetName())
+	if !j.Store.Has(fn) {
+		http.NotFound(w, r)
+		return
+	}
+	d, err := j.Store.Read(fn)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	j.writeJwt(w, r, d)
+}
+
+func (j *JwtServer) getAccount(w http.ResponseWriter, r *http.Request, pubkey string) {
+	name, err := j.Store.FindAccount(pubkey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	d, err := j.Store.Read(store.Accounts, name, store.JwtName(name))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	j.writeJwt(w, r, d)
+}
+
+// writeJwt writes the token with an ETag derived from its JTI, honoring If-None-Match
+func (j *JwtServer) writeJwt(w http.ResponseWriter, r *http.Request, token []byte) {
+	gc, err := jwt.DecodeGeneric(string(token))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid jwt: %v", err), http.StatusInternalServerError)
+		return
+	}
+	etag := fmt.Sprintf("%q", gc.ID)
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", fmt.Sprintf("max-age=%d", int64(j.MaxAge/time.Second)))
+	h.Set("Last-Modified", time.Unix(gc.IssuedAt, 0).UTC().Format(http.TimeFormat))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.Set("Content-Type", "application/jwt")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(token)
+	}
+}
+
+func (j *JwtServer) postAccount(w http.ResponseWriter, r *http.Request, pubkey string) {
+	if j.ReadOnly {
+		http.Error(w, "server is read-only", http.StatusForbidden)
+		return
+	}
+	d, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, jwtServerMaxUpload))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error reading request: %v", err), http.StatusBadRequest)
+		return
+	}
+	token := strings.TrimSpace(string(d))
+	ac, err := jwt.DecodeAccountClaims(token)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid account jwt: %v", err), http.StatusBadRequest)
+		return
+	}
+	if ac.Subject != pubkey {
+		http.Error(w, fmt.Sprintf("account jwt subject %q doesn't match %q", ac.Subject, pubkey), http.StatusBadRequest)
+		return
+	}
+	if ac.Name == "" {
+		http.Error(w, "account jwt doesn't have a name", http.StatusBadRequest)
+		return
+	}
+	vr := jwt.CreateValidationResults()
+	ac.Validate(vr)
+	if vr.IsBlocking(true) {
+		http.Error(w, fmt.Sprintf("account jwt is not valid: %v", vr.Issues[0].Description), http.StatusBadRequest)
+		return
+	}
+
+	j.Lock()
+	defer j.Unlock()
+	if err := j.Store.LockDir(); err != nil {
+		status := http.StatusInternalServerError
+		if err == store.ErrStoreLocked {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	defer j.Store.UnlockDir()
+
+	oc, err := j.operator()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !oc.DidSign(ac) {
+		http.Error(w, fmt.Sprintf("account jwt is not signed by operator %q", oc.Subject), http.StatusForbidden)
+		return
+	}
+
+	status := http.StatusCreated
+	name, err := j.Store.FindAccount(pubkey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if name != "" {
+		status = http.StatusOK
+		if name != ac.Name {
+			http.Error(w, fmt.Sprintf("account %q is stored as %q and cannot be renamed", pubkey, name), http.StatusConflict)
+			return
+		}
+		current, err := j.Store.ReadAccountClaim(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if current != nil && current.IssuedAt > ac.IssuedAt {
+			http.Error(w, fmt.Sprintf("account jwt is older than the stored jwt %q", current.ID), http.StatusConflict)
+			return
+		}
+	} else if j.Store.Has(store.Accounts, ac.Name, store.JwtName(ac.Name)) {
+		http.Error(w, fmt.Sprintf("account name %q is used by a different account", ac.Name), http.StatusConflict)
+		return
+	}
+
+	if err := j.Store.StoreClaim([]byte(token)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := auditChanges(j.Store, "nsc serve", map[string]string{"remote": r.RemoteAddr}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", fmt.Sprintf("%q", ac.ID))
+	w.WriteHeader(status)
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, url string, etag string) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	d, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(d)
+}
+
+func post(t *testing.T, url string, token string) *http.Response {
+	resp, err := http.Post(url, "application/jwt", strings.NewReader(token))
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func Test_ServeAccount(t *testing.T) {
+	ts := NewTestStore(t, "serve")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	ac, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(NewJwtServer(ts.Store))
+	defer srv.Close()
+
+	resp, body := get(t, srv.URL+jwtServerAccountsPath+ac.Subject, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/jwt", resp.Header.Get("Content-Type"))
+	require.Equal(t, "max-age=60", resp.Header.Get("Cache-Control"))
+	etag := resp.Header.Get("ETag")
+	require.Equal(t, `"`+ac.ID+`"`, etag)
+	c, err := jwt.DecodeAccountClaims(body)
+	require.NoError(t, err)
+	require.Equal(t, ac.ID, c.ID)
+
+	resp, body = get(t, srv.URL+jwtServerAccountsPath+ac.Subject, etag)
+	require.Equal(t, http.StatusNotModified, resp.StatusCode)
+	require.Empty(t, body)
+
+	_, apub, _ := CreateAccountKey(t)
+	resp, _ = get(t, srv.URL+jwtServerAccountsPath+apub, "")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func Test_ServeOperator(t *testing.T) {
+	ts := NewTestStore(t, "serve")
+	defer ts.Done(t)
+
+	srv := httptest.NewServer(NewJwtServer(ts.Store))
+	defer srv.Close()
+
+	resp, body := get(t, srv.URL+jwtServerOperatorPath, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	oc, err := jwt.DecodeOperatorClaims(body)
+	require.NoError(t, err)
+	require.True(t, nkeys.IsValidPublicOperatorKey(oc.Subject))
+	require.Equal(t, `"`+oc.ID+`"`, resp.Header.Get("ETag"))
+}
+
+func Test_ServePostAccount(t *testing.T) {
+	ts := NewTestStore(t, "serve")
+	defer ts.Done(t)
+	// only audit the posted accounts
+	ts.Store.TakeChanges()
+
+	srv := httptest.NewServer(NewJwtServer(ts.Store))
+	defer srv.Close()
+
+	opk, err := ts.KeyStore.GetOperatorKey("serve")
+	require.NoError(t, err)
+	_, apub, _ := CreateAccountKey(t)
+	ac := jwt.NewAccountClaims(apub)
+	ac.Name = "A"
+	token, err := ac.Encode(opk)
+	require.NoError(t, err)
+
+	resp := post(t, srv.URL+jwtServerAccountsPath+apub, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	sc, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Equal(t, apub, sc.Subject)
+
+	ac.Tags.Add("updated")
+	token, err = ac.Encode(opk)
+	require.NoError(t, err)
+	resp = post(t, srv.URL+jwtServerAccountsPath+apub, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sc, err = ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	require.Contains(t, sc.Tags, "updated")
+
+	records, err := ReadAuditLog(ts.Store.Dir)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	for _, r := range records {
+		require.Equal(t, "nsc serve", r.Command)
+		require.NotEmpty(t, r.Flags["remote"])
+		require.Equal(t, apub, r.PublicKey)
+	}
+	require.Empty(t, records[0].PrevID)
+	require.Equal(t, records[0].ID, records[1].PrevID)
+	require.Equal(t, sc.ID, records[1].ID)
+	require.Empty(t, ts.Store.TakeChanges())
+}
+
+func Test_ServePostAccountRejected(t *testing.T) {
+	ts := NewTestStore(t, "serve")
+	defer ts.Done(t)
+
+	js := NewJwtServer(ts.Store)
+	srv := httptest.NewServer(js)
+	defer srv.Close()
+
+	ts.AddAccount(t, "A")
+	opk, err := ts.KeyStore.GetOperatorKey("serve")
+	require.NoError(t, err)
+	_, apub, akp := CreateAccountKey(t)
+	ac := jwt.NewAccountClaims(apub)
+	ac.Name = "B"
+
+	// self-signed
+	token, err := ac.Encode(akp)
+	require.NoError(t, err)
+	resp := post(t, srv.URL+jwtServerAccountsPath+apub, token)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// wrong subject
+	token, err = ac.Encode(opk)
+	require.NoError(t, err)
+	_, other, _ := CreateAccountKey(t)
+	resp = post(t, srv.URL+jwtServerAccountsPath+other, token)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// name used by a different account
+	ac.Name = "A"
+	token, err = ac.Encode(opk)
+	require.NoError(t, err)
+	resp = post(t, srv.URL+jwtServerAccountsPath+apub, token)
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// garbage
+	resp = post(t, srv.URL+jwtServerAccountsPath+apub, "hello")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// read-only
+	js.ReadOnly = true
+	ac.Name = "B"
+	token, err = ac.Encode(opk)
+	require.NoError(t, err)
+	resp = post(t, srv.URL+jwtServerAccountsPath+apub, token)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.False(t, ts.Store.Has(store.Accounts, "B"))
+}
+
+func Test_ServeShutdown(t *testing.T) {
+	ts := NewTestStore(t, "serve")
+	defer ts.Done(t)
+
+	ctx, err := NewActx(createServeCmd(), nil)
+	require.NoError(t, err)
+	p := &ServeParams{server: NewJwtServer(ts.Store)}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	stop := make(chan os.Signal, 1)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- p.serve(ctx, l, stop)
+	}()
+
+	resp, _ := get(t, fmt.Sprintf("http://%s%s", l.Addr().String(), jwtServerOperatorPath), "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	stop <- os.Interrupt
+	select {
+	case err := <-errs:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("server didn't shut down")
+	}
+}
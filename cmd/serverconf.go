@@ -0,0 +1,247 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseServerConfig parses a nats-server configuration into maps, lists and
+// values. Keys are separated from their values by '=', ':' or whitespace,
+// entries by newlines, ',' or ';', and '$NAME' references a variable defined
+// in an enclosing block or the environment. Include directives are not supported.
+func ParseServerConfig(data string) (map[string]interface{}, error) {
+	p := &confParser{data: []rune(data), line: 1}
+	m := make(map[string]interface{})
+	if err := p.parseMap(m, 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type confParser struct {
+	data   []rune
+	pos    int
+	line   int
+	scopes []map[string]interface{}
+}
+
+func (p *confParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *confParser) peek() (rune, bool) {
+	if p.pos >= len(p.data) {
+		return 0, false
+	}
+	return p.data[p.pos], true
+}
+
+// skip skips blanks and comments, and newlines and separators if entries is set
+func (p *confParser) skip(entries bool) {
+	for p.pos < len(p.data) {
+		r := p.data[p.pos]
+		switch {
+		case r == '\n' && entries:
+			p.line++
+		case (r == ',' || r == ';') && entries:
+		case r == '\n' || r == ',' || r == ';':
+			return
+		case unicode.IsSpace(r):
+		case r == '#' || (r == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/'):
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+// parseMap reads entries into m until the end rune, or the end of the data for the top level
+func (p *confParser) parseMap(m map[string]interface{}, end rune) error {
+	p.scopes = append(p.scopes, m)
+	defer func() { p.scopes = p.scopes[:len(p.scopes)-1] }()
+	for {
+		p.skip(true)
+		r, ok := p.peek()
+		if !ok {
+			if end != 0 {
+				return p.errorf("expected %q", end)
+			}
+			return nil
+		}
+		if r == end {
+			p.pos++
+			return nil
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		if key == "include" {
+			return p.errorf("include directives are not supported")
+		}
+		p.skip(false)
+		if r, ok := p.peek(); ok && (r == '=' || r == ':') {
+			p.pos++
+			p.skip(false)
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		m[key] = v
+	}
+}
+
+func (p *confParser) parseKey() (string, error) {
+	r, _ := p.peek()
+	if r == '"' || r == '\'' {
+		return p.parseQuoted()
+	}
+	start := p.pos
+	for p.pos < len(p.data) {
+		r := p.data[p.pos]
+		if unicode.IsSpace(r) || strings.ContainsRune("=:{}[],;#", r) {
+			break
+		}
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("unexpected %q", r)
+	}
+	return string(p.data[start:p.pos]), nil
+}
+
+func (p *confParser) parseValue() (interface{}, error) {
+	r, ok := p.peek()
+	if !ok {
+		return nil, p.errorf("expected a value")
+	}
+	switch r {
+	case '{':
+		p.pos++
+		m := make(map[string]interface{})
+		if err := p.parseMap(m, '}'); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case '[':
+		p.pos++
+		return p.parseArray()
+	case '"', '\'':
+		return p.parseQuoted()
+	}
+
+	start := p.pos
+	for p.pos < len(p.data) {
+		r := p.data[p.pos]
+		if unicode.IsSpace(r) || strings.ContainsRune(",;}]#", r) {
+			break
+		}
+		p.pos++
+	}
+	v := string(p.data[start:p.pos])
+	if v == "" {
+		return nil, p.errorf("unexpected %q", r)
+	}
+	if strings.HasPrefix(v, "$") {
+		return p.lookup(v[1:])
+	}
+	switch strings.ToLower(v) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off":
+		return false, nil
+	}
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f, nil
+	}
+	return v, nil
+}
+
+func (p *confParser) parseArray() ([]interface{}, error) {
+	a := make([]interface{}, 0)
+	for {
+		p.skip(true)
+		r, ok := p.peek()
+		if !ok {
+			return nil, p.errorf("expected ']'")
+		}
+		if r == ']' {
+			p.pos++
+			return a, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+}
+
+func (p *confParser) parseQuoted() (string, error) {
+	q := p.data[p.pos]
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.data) {
+		r := p.data[p.pos]
+		p.pos++
+		switch {
+		case r == q:
+			return b.String(), nil
+		case r == '\n':
+			return "", p.errorf("unterminated string")
+		case r == '\\' && q == '"' && p.pos < len(p.data):
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			default:
+				b.WriteRune(e)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *confParser) lookup(name string) (interface{}, error) {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if v, ok := p.scopes[i][name]; ok {
+			return v, nil
+		}
+	}
+	if v, ok := os.LookupEnv(name); ok {
+		return v, nil
+	}
+	return nil, p.errorf("variable %q is not defined", name)
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseServerConfig(t *testing.T) {
+	conf := `
+# comment
+port: 4222
+debug = true
+listen 127.0.0.1:4222 // trailing comment
+SUBS = ["a.>", b.>]
+authorization {
+  users = [
+    {user: alice, password: "s3cr#t", permissions: {subscribe: $SUBS}}
+    {user: 'bob'; password: pwd}
+  ]
+}
+`
+	m, err := ParseServerConfig(conf)
+	require.NoError(t, err)
+	require.Equal(t, int64(4222), m["port"])
+	require.Equal(t, true, m["debug"])
+	require.Equal(t, "127.0.0.1:4222", m["listen"])
+
+	users := m["authorization"].(map[string]interface{})["users"].([]interface{})
+	require.Len(t, users, 2)
+	alice := users[0].(map[string]interface{})
+	require.Equal(t, "alice", alice["user"])
+	require.Equal(t, "s3cr#t", alice["password"])
+	perms := alice["permissions"].(map[string]interface{})
+	require.Equal(t, []interface{}{"a.>", "b.>"}, perms["subscribe"])
+	require.Equal(t, "bob", users[1].(map[string]interface{})["user"])
+}
+
+func Test_ParseServerConfigErrors(t *testing.T) {
+	tests := []struct {
+		conf string
+		err  string
+	}{
+		{"a {\n b: 1\n", `line 3: expected '}'`},
+		{"a: [1, 2", `line 1: expected ']'`},
+		{"a: \"x\n", "line 1: unterminated string"},
+		{"a: $NSC_UNDEFINED_VARIABLE", `variable "NSC_UNDEFINED_VARIABLE" is not defined`},
+		{"include ./auth.conf", "include directives are not supported"},
+	}
+	for _, tt := range tests {
+		_, err := ParseServerConfig(tt.conf)
+		require.Error(t, err, tt.conf)
+		require.Contains(t, err.Error(), tt.err, tt.conf)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+)
+
+// ServerSync lists the servers of a cluster that embedded another cluster JWT
+type ServerSync struct {
+	Cluster  string
+	Reissued []string
+	Skipped  []string
+}
+
+// SyncServers re-issues the servers of the cluster that embed another JWT than
+// the cluster token. Servers are signed with the cluster key, servers are
+// skipped if the keystore doesn't have it.
+func SyncServers(ctx ActionCtx, cluster string, token string) (*ServerSync, error) {
+	sctx := ctx.StoreCtx()
+	s := sctx.Store
+	sync := &ServerSync{Cluster: cluster}
+	servers, err := s.ListEntries(store.Clusters, cluster, store.Servers)
+	if err != nil {
+		return nil, err
+	}
+	ckp, err := sctx.KeyStore.GetClusterKey(cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range servers {
+		sc, err := s.ReadServerClaim(cluster, n)
+		if err != nil {
+			return nil, err
+		}
+		if sc == nil || sc.Cluster == token {
+			continue
+		}
+		if ckp == nil || !store.Match(sc.Issuer, ckp) {
+			sync.Skipped = append(sync.Skipped, n)
+			continue
+		}
+		sc.Cluster = token
+		st, err := sc.Encode(ckp)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.StoreClaim([]byte(st)); err != nil {
+			return nil, err
+		}
+		sync.Reissued = append(sync.Reissued, n)
+	}
+	return sync, nil
+}
+
+// Print reports the servers re-issued and the servers left with a stale cluster JWT
+func (s *ServerSync) Print(cmd *cobra.Command) {
+	if s == nil {
+		return
+	}
+	for _, n := range s.Reissued {
+		cmd.Printf("Re-issued server %q with the new cluster JWT\n", n)
+	}
+	for _, n := range s.Skipped {
+		cmd.Printf("Server %q has a stale cluster JWT - the seed of cluster %q is required to re-issue it\n", n, s.Cluster)
+	}
+}
+
+// ClusterChange is a field that differs between two versions of a cluster JWT
+type ClusterChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// DiffClusters returns the fields of the cluster that changed from the embedded claim
+func DiffClusters(from *jwt.ClusterClaims, to *jwt.ClusterClaims) []ClusterChange {
+	if from == nil {
+		from = &jwt.ClusterClaims{}
+	}
+	if to == nil {
+		to = &jwt.ClusterClaims{}
+	}
+	var changes []ClusterChange
+	add := func(field string, a string, b string) {
+		if a != b {
+			changes = append(changes, ClusterChange{Field: field, From: a, To: b})
+		}
+	}
+	expires := func(v int64) string {
+		if v == 0 {
+			return "No expiration"
+		}
+		return UnixToDate(v)
+	}
+	add("Name", from.Name, to.Name)
+	add("Issuer ID", from.Issuer, to.Issuer)
+	add("Trusted Operators", strings.Join(from.Trust, ", "), strings.Join(to.Trust, ", "))
+	add("Operator Srv", from.OperatorURL, to.OperatorURL)
+	add("Trusted Accounts", strings.Join(from.Accounts, ", "), strings.Join(to.Accounts, ", "))
+	add("Account Srv", from.AccountURL, to.AccountURL)
+	add("Expires", expires(from.Expires), expires(to.Expires))
+	add("Tags", strings.Join(from.Tags, ", "), strings.Join(to.Tags, ", "))
+	return changes
+}
+
+// staleCluster compares the cluster JWT embedded in the server with the JWT of
+// the cluster in the store, and returns the changes if they differ
+func staleCluster(s *store.Store, cluster string, sc *jwt.ServerClaims) (bool, []ClusterChange, error) {
+	d, err := s.Read(store.Clusters, cluster, store.JwtName(cluster))
+	if err != nil {
+		return false, nil, err
+	}
+	token := string(d)
+	if sc.Cluster == token {
+		return false, nil, nil
+	}
+	current, err := jwt.DecodeClusterClaims(token)
+	if err != nil {
+		return false, nil, fmt.Errorf("error decoding the cluster JWT: %v", err)
+	}
+	var embedded *jwt.ClusterClaims
+	if sc.Cluster != "" {
+		// an embedded JWT that doesn't decode shows every field as changed
+		embedded, _ = jwt.DecodeClusterClaims(sc.Cluster)
+	}
+	return true, DiffClusters(embedded, current), nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// SettingEnvPrefix is the prefix of the environment variables that provide settings
+const SettingEnvPrefix = "NSC"
+
+const (
+	StoreSetting      = "store"
+	OperatorSetting   = "operator"
+	AccountSetting    = "account"
+	ClusterSetting    = "cluster"
+	PrivateKeySetting = "private_key"
+)
+
+// setting is a value that can be provided by the config file or the environment
+type setting struct {
+	key string
+	get func() string
+	set func(string)
+}
+
+var settings = []setting{
+	{StoreSetting, func() string { return config.StoreRoot }, func(v string) { config.StoreRoot = v }},
+	{OperatorSetting, func() string { return config.Operator }, func(v string) { config.Operator = v }},
+	{AccountSetting, func() string { return config.Account }, func(v string) { config.Account = v }},
+	{ClusterSetting, func() string { return config.Cluster }, func(v string) { config.Cluster = v }},
+	{PrivateKeySetting, func() string { return KeyPathFlag }, func(v string) { KeyPathFlag = v }},
+}
+
+// settingSources records where settings that don't come from the tool configuration were set
+var settingSources = make(map[string]string)
+
+// clearContextSources forgets the sources of the store, operator, account and cluster
+// settings, after they are replaced by a context
+func clearContextSources() {
+	for _, k := range []string{StoreSetting, OperatorSetting, AccountSetting, ClusterSetting} {
+		delete(settingSources, k)
+	}
+}
+
+// SettingEnv returns the name of the environment variable for a setting
+func SettingEnv(key string) string {
+	return fmt.Sprintf("%s_%s", SettingEnvPrefix, strings.ToUpper(key))
+}
+
+// SettingSource describes where the effective value of a setting came from
+func SettingSource(key string) string {
+	if s, ok := settingSources[key]; ok {
+		return s
+	}
+	if key == PrivateKeySetting {
+		return ""
+	}
+	return storeSource
+}
+
+// ApplySettings overrides the settings with the values in the config file read by v,
+// and then with the values of the NSC_* environment variables. The values are not saved.
+// Settings already provided by a flag are left as is.
+func (d *ToolConfig) ApplySettings(v *viper.Viper) error {
+	keyFlag := KeyPathFlag != ""
+	if keyFlag {
+		settingSources[PrivateKeySetting] = "--private-key flag"
+	}
+	c := d.ContextConfig
+	changed := make(map[string]bool)
+	for _, s := range settings {
+		if s.key == PrivateKeySetting && keyFlag {
+			continue
+		}
+		if v != nil && v.ConfigFileUsed() != "" && v.InConfig(s.key) {
+			s.set(v.GetString(s.key))
+			settingSources[s.key] = fmt.Sprintf("config file %q", v.ConfigFileUsed())
+			changed[s.key] = true
+		}
+		if ev := os.Getenv(SettingEnv(s.key)); ev != "" {
+			s.set(ev)
+			settingSources[s.key] = "$" + SettingEnv(s.key)
+			changed[s.key] = true
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	// a different store may not have the operator, account or cluster of the
+	// configuration, so unless they were set too, deduce them from the store
+	if changed[StoreSetting] && d.StoreRoot != c.StoreRoot {
+		nc, err := NewContextConfig(d.StoreRoot)
+		if err != nil {
+			return err
+		}
+		d.StoreRoot = nc.StoreRoot
+		deduced := map[string]string{OperatorSetting: nc.Operator, AccountSetting: nc.Account, ClusterSetting: nc.Cluster}
+		for _, s := range settings {
+			if v, ok := deduced[s.key]; ok && !changed[s.key] {
+				s.set(v)
+				settingSources[s.key] = settingSources[StoreSetting]
+			}
+		}
+	}
+	nc := d.ContextConfig
+	d.ContextConfig = c
+	d.override(nc)
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SettingsFromEnv(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer configHome(t)()
+
+	ts.AddAccount(t, "A")
+	ts.AddAccount(t, "B")
+	ForceAccount(t, "A")
+
+	require.NoError(t, os.Setenv(SettingEnv(AccountSetting), "B"))
+	defer os.Unsetenv(SettingEnv(AccountSetting))
+
+	conf := GetConfig()
+	require.NoError(t, conf.ApplySettings(nil))
+	require.Equal(t, "B", conf.Account)
+	require.Equal(t, "$NSC_ACCOUNT", SettingSource(AccountSetting))
+	require.Equal(t, defaultStoreSource, SettingSource(OperatorSetting))
+
+	_, stderr, err := ExecuteCmd(createEnvCmd())
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "Default Account B $NSC_ACCOUNT")
+
+	// the value from the environment is not saved
+	require.NoError(t, conf.Save())
+	var saved ToolConfig
+	require.NoError(t, ReadJson(conf.configFile(), &saved))
+	require.Equal(t, "A", saved.Account)
+}
+
+func Test_SettingsFromConfigFile(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer configHome(t)()
+	defer func() {
+		cfgFile = ""
+		KeyPathFlag = ""
+	}()
+
+	ts.AddAccount(t, "A")
+	ts.AddAccount(t, "B")
+	ts.AddCluster(t, "C")
+
+	fp := filepath.Join(ts.Dir, "nsc.yaml")
+	require.NoError(t, ioutil.WriteFile(fp, []byte("account: B\ncluster: C\nprivate_key: /tmp/key.nk\n"), 0600))
+	cfgFile = fp
+	initConfig()
+	require.NoError(t, configErr)
+
+	conf := GetConfig()
+	require.Equal(t, "B", conf.Account)
+	require.Equal(t, "C", conf.Cluster)
+	require.Equal(t, "/tmp/key.nk", KeyPathFlag)
+	require.Contains(t, SettingSource(AccountSetting), "config file")
+	require.Contains(t, SettingSource(PrivateKeySetting), "config file")
+
+	// the environment wins over the config file
+	require.NoError(t, os.Setenv(SettingEnv(AccountSetting), "A"))
+	defer os.Unsetenv(SettingEnv(AccountSetting))
+	KeyPathFlag = ""
+	initConfig()
+	require.NoError(t, configErr)
+	require.Equal(t, "A", conf.Account)
+	require.Equal(t, "$NSC_ACCOUNT", SettingSource(AccountSetting))
+
+	_, stderr, err := ExecuteCmd(createEnvCmd())
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "Config File Yes "+fp)
+	require.Contains(t, stderr, "Default Cluster C config file")
+}
+
+func Test_SettingsConfigFileMustExist(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer func() {
+		cfgFile = ""
+		configErr = nil
+	}()
+
+	cfgFile = filepath.Join(ts.Dir, "missing.yaml")
+	initConfig()
+	require.Error(t, configErr)
+	require.Contains(t, configErr.Error(), "doesn't exist")
+}
+
+func Test_SettingsStoreDeducesOperator(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	ForceAccount(t, "A")
+
+	root := MakeTempDir(t)
+	_, _, kp := CreateOperatorKey(t)
+	_, err := store.CreateStore("other", root, &store.NamedKey{Name: "other", KP: kp})
+	require.NoError(t, err)
+
+	require.NoError(t, os.Setenv(SettingEnv(StoreSetting), root))
+	defer os.Unsetenv(SettingEnv(StoreSetting))
+
+	conf := GetConfig()
+	require.NoError(t, conf.ApplySettings(nil))
+	require.Equal(t, root, conf.StoreRoot)
+	require.Equal(t, "other", conf.Operator)
+	require.Empty(t, conf.Account)
+	require.Equal(t, "$NSC_STORE", SettingSource(OperatorSetting))
+
+	s, err := GetStore()
+	require.NoError(t, err)
+	require.Equal(t, "other", s.GetName())
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Shamir secret sharing over GF(256) with the AES polynomial. Each byte of the
+// secret is the constant term of a random polynomial of degree threshold-1,
+// and share i holds the evaluations of the polynomials at x=i.
+
+var gfExp [510]byte
+var gfLog [256]byte
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		gfExp[i] = x
+		gfExp[i+255] = x
+		gfLog[x] = byte(i)
+		// multiply by the generator 3
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// SecretShare is the share with index X of a secret
+type SecretShare struct {
+	X         byte
+	Threshold byte
+	Data      []byte
+}
+
+// SplitSecret splits the secret into n shares, any threshold of them recover it
+func SplitSecret(secret []byte, n int, threshold int) ([]SecretShare, error) {
+	if threshold < 2 || threshold > n {
+		return nil, fmt.Errorf("the threshold must be between 2 and the number of shares")
+	}
+	if n > 255 {
+		return nil, fmt.Errorf("at most 255 shares are supported")
+	}
+	shares := make([]SecretShare, n)
+	for i := range shares {
+		shares[i] = SecretShare{X: byte(i + 1), Threshold: byte(threshold), Data: make([]byte, len(secret))}
+	}
+	coefficients := make([]byte, threshold)
+	for j, b := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		coefficients[0] = b
+		for i := range shares {
+			// Horner's rule
+			var y byte
+			for k := threshold - 1; k >= 0; k-- {
+				y = gfMul(y, shares[i].X) ^ coefficients[k]
+			}
+			shares[i].Data[j] = y
+		}
+	}
+	return shares, nil
+}
+
+// CombineShares recovers a secret from at least threshold of its shares
+func CombineShares(shares []SecretShare) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares")
+	}
+	threshold := int(shares[0].Threshold)
+	size := len(shares[0].Data)
+	seen := make(map[byte]bool)
+	for _, s := range shares {
+		if int(s.Threshold) != threshold || len(s.Data) != size {
+			return nil, errors.New("the shares are not from the same secret")
+		}
+		if s.X == 0 || seen[s.X] {
+			return nil, fmt.Errorf("share %d is repeated or invalid", s.X)
+		}
+		seen[s.X] = true
+	}
+	if len(shares) < threshold {
+		return nil, fmt.Errorf("%d shares are required, only %d were provided", threshold, len(shares))
+	}
+	shares = shares[:threshold]
+
+	secret := make([]byte, size)
+	for j := range secret {
+		// Lagrange interpolation at x=0
+		var y byte
+		for i, si := range shares {
+			l := byte(1)
+			for k, sk := range shares {
+				if i != k {
+					l = gfMul(l, gfDiv(sk.X, sk.X^si.X))
+				}
+			}
+			y ^= gfMul(si.Data[j], l)
+		}
+		secret[j] = y
+	}
+	return secret, nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ShamirSplitCombine(t *testing.T) {
+	secret := []byte("SOALU7LPGJK2BDF7IHD7UZT6ZM23UMKYLGJLNTT7CJOWV5ESHHBWQL4TZAE")
+	shares, err := SplitSecret(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	for _, set := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var subset []SecretShare
+		for _, i := range set {
+			subset = append(subset, shares[i])
+		}
+		v, err := CombineShares(subset)
+		require.NoError(t, err)
+		require.Equal(t, secret, v)
+	}
+
+	_, err = CombineShares(shares[:2])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "3 shares are required")
+
+	_, err = CombineShares([]SecretShare{shares[0], shares[0], shares[1]})
+	require.Error(t, err)
+
+	_, err = SplitSecret(secret, 2, 3)
+	require.Error(t, err)
+}
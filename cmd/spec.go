@@ -0,0 +1,201 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/nats-io/jwt"
+	"gopkg.in/yaml.v2"
+)
+
+// Spec is a declarative description of operators and the entities they manage.
+// Entities are identified by name, the optional public keys pin their identity.
+type Spec struct {
+	Operators []*OperatorSpec `json:"operators" yaml:"operators"`
+}
+
+type OperatorSpec struct {
+	Name      string         `json:"name" yaml:"name"`
+	PublicKey string         `json:"public_key,omitempty" yaml:"public_key,omitempty"`
+	Accounts  []*AccountSpec `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+	Clusters  []*ClusterSpec `json:"clusters,omitempty" yaml:"clusters,omitempty"`
+}
+
+type AccountSpec struct {
+	Name      string        `json:"name" yaml:"name"`
+	PublicKey string        `json:"public_key,omitempty" yaml:"public_key,omitempty"`
+	Tags      []string      `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Limits    LimitsSpec    `json:"limits,omitempty" yaml:"limits,omitempty"`
+	Exports   []*ExportSpec `json:"exports,omitempty" yaml:"exports,omitempty"`
+	Imports   []*ImportSpec `json:"imports,omitempty" yaml:"imports,omitempty"`
+	Users     []*UserSpec   `json:"users,omitempty" yaml:"users,omitempty"`
+}
+
+// LimitsSpec are the account limits managed by the spec, a zero value leaves the limit untouched
+type LimitsSpec struct {
+	Conns int64 `json:"conns,omitempty" yaml:"conns,omitempty"`
+}
+
+type ExportSpec struct {
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Subject string `json:"subject" yaml:"subject"`
+	// stream (default) or service
+	Type    string `json:"type,omitempty" yaml:"type,omitempty"`
+	Private bool   `json:"private,omitempty" yaml:"private,omitempty"`
+}
+
+// ImportSpec imports a subject either from a private export of an account
+// in the same operator, for which an activation is generated, or using an
+// activation token that is inline or read from a file or url
+type ImportSpec struct {
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Account string `json:"account,omitempty" yaml:"account,omitempty"`
+	Subject string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	To      string `json:"to,omitempty" yaml:"to,omitempty"`
+	Token   string `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+type UserSpec struct {
+	Name           string   `json:"name" yaml:"name"`
+	PublicKey      string   `json:"public_key,omitempty" yaml:"public_key,omitempty"`
+	AllowPub       []string `json:"allow_pub,omitempty" yaml:"allow_pub,omitempty"`
+	AllowSub       []string `json:"allow_sub,omitempty" yaml:"allow_sub,omitempty"`
+	DenyPub        []string `json:"deny_pub,omitempty" yaml:"deny_pub,omitempty"`
+	DenySub        []string `json:"deny_sub,omitempty" yaml:"deny_sub,omitempty"`
+	SourceNetworks []string `json:"source_networks,omitempty" yaml:"source_networks,omitempty"`
+	Tags           []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type ClusterSpec struct {
+	Name                string `json:"name" yaml:"name"`
+	PublicKey           string `json:"public_key,omitempty" yaml:"public_key,omitempty"`
+	AccountURLTemplate  string `json:"account_url_template,omitempty" yaml:"account_url_template,omitempty"`
+	OperatorURLTemplate string `json:"operator_url_template,omitempty" yaml:"operator_url_template,omitempty"`
+	// account names in the operator or account public keys
+	TrustedAccounts []string `json:"trusted_accounts,omitempty" yaml:"trusted_accounts,omitempty"`
+	// operator names in the stores directory or operator public keys
+	TrustedOperators []string      `json:"trusted_operators,omitempty" yaml:"trusted_operators,omitempty"`
+	Tags             []string      `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Servers          []*ServerSpec `json:"servers,omitempty" yaml:"servers,omitempty"`
+}
+
+type ServerSpec struct {
+	Name      string   `json:"name" yaml:"name"`
+	PublicKey string   `json:"public_key,omitempty" yaml:"public_key,omitempty"`
+	Tags      []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// LoadSpec reads a YAML or JSON spec
+func LoadSpec(fp string) (*Spec, error) {
+	d, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q: %v", fp, err)
+	}
+	return ParseSpec(d)
+}
+
+// ParseSpec parses a YAML or JSON spec, JSON being a subset of YAML
+func ParseSpec(d []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.UnmarshalStrict(d, &spec); err != nil {
+		return nil, fmt.Errorf("error parsing spec: %v", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks the structure of the spec, the values are validated by
+// the actions that apply them
+func (s *Spec) Validate() error {
+	operators := make(map[string]bool)
+	for _, o := range s.Operators {
+		if err := uniqueName("operator", "", o.Name, operators); err != nil {
+			return err
+		}
+		accounts := make(map[string]bool)
+		for _, a := range o.Accounts {
+			if err := uniqueName("account", o.Name, a.Name, accounts); err != nil {
+				return err
+			}
+			for _, e := range a.Exports {
+				if e.Subject == "" {
+					return fmt.Errorf("an export in account %q doesn't have a subject", a.Name)
+				}
+				if _, err := e.ExportType(); err != nil {
+					return fmt.Errorf("export %q in account %q: %v", e.Subject, a.Name, err)
+				}
+			}
+			for _, im := range a.Imports {
+				if im.Token == "" && (im.Account == "" || im.Subject == "") {
+					return fmt.Errorf("an import in account %q requires a token or an account and subject", a.Name)
+				}
+				if im.Account == a.Name {
+					return fmt.Errorf("account %q cannot import from itself", a.Name)
+				}
+			}
+			users := make(map[string]bool)
+			for _, u := range a.Users {
+				if err := uniqueName("user", a.Name, u.Name, users); err != nil {
+					return err
+				}
+			}
+		}
+		clusters := make(map[string]bool)
+		for _, c := range o.Clusters {
+			if err := uniqueName("cluster", o.Name, c.Name, clusters); err != nil {
+				return err
+			}
+			servers := make(map[string]bool)
+			for _, sv := range c.Servers {
+				if err := uniqueName("server", c.Name, sv.Name, servers); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func uniqueName(kind string, parent string, name string, names map[string]bool) error {
+	in := ""
+	if parent != "" {
+		in = fmt.Sprintf(" in %q", parent)
+	}
+	if name == "" {
+		return fmt.Errorf("a %s%s doesn't have a name", kind, in)
+	}
+	if names[name] {
+		return fmt.Errorf("%s %q is specified more than once%s", kind, name, in)
+	}
+	names[name] = true
+	return nil
+}
+
+// ExportType returns the jwt export type, exports are streams unless specified
+func (e *ExportSpec) ExportType() (jwt.ExportType, error) {
+	switch e.Type {
+	case "", jwt.Stream.String():
+		return jwt.Stream, nil
+	case jwt.Service.String():
+		return jwt.Service, nil
+	default:
+		return jwt.Unknown, fmt.Errorf("unknown export type %q", e.Type)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/jwt"
+)
+
+const Activations = "activations"
+const activationExtension = ".json"
+
+// ActivationChange is the kind of the changes recorded for activations
+const ActivationChange = "activation"
+
+// ActivationRecord tracks an activation token issued by an account
+type ActivationRecord struct {
+	ID        string `json:"jti"`
+	Target    string `json:"target"`
+	Subject   string `json:"subject"`
+	Type      string `json:"type"`
+	IssuedAt  int64  `json:"iat"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	Expires   int64  `json:"exp,omitempty"`
+	Revoked   int64  `json:"revoked,omitempty"`
+	RenewedBy string `json:"renewed_by,omitempty"`
+	Token     string `json:"token"`
+}
+
+// NewActivationRecord creates a record from an encoded activation token
+func NewActivationRecord(token string) (*ActivationRecord, error) {
+	ac, err := jwt.DecodeActivationClaims(token)
+	if err != nil {
+		return nil, err
+	}
+	return &ActivationRecord{
+		ID:        ac.ID,
+		Target:    ac.Subject,
+		Subject:   string(ac.ImportSubject),
+		Type:      ac.ImportType.String(),
+		IssuedAt:  ac.IssuedAt,
+		NotBefore: ac.NotBefore,
+		Expires:   ac.Expires,
+		Token:     token,
+	}, nil
+}
+
+func (r *ActivationRecord) IsRevoked() bool {
+	return r.Revoked > 0
+}
+
+// IsExpired returns true if the activation expired before the specified unix time
+func (r *ActivationRecord) IsExpired(now int64) bool {
+	return r.Expires > 0 && r.Expires < now
+}
+
+func activationFileName(jti string) string {
+	return jti + activationExtension
+}
+
+// ValidateJTI checks that a JTI is only letters and digits, as the JTIs of the
+// JWTs are, so that it can name a file in the store
+func ValidateJTI(jti string) error {
+	if jti == "" {
+		return fmt.Errorf("jti cannot be empty")
+	}
+	for _, r := range jti {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return fmt.Errorf("%q is not a valid jti - only letters and digits are allowed", jti)
+		}
+	}
+	return nil
+}
+
+// StoreActivation records an activation issued by the specified account
+func (s *Store) StoreActivation(account string, r *ActivationRecord) error {
+	if err := ValidateJTI(r.ID); err != nil {
+		return err
+	}
+	d, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing activation %q: %v", r.ID, err)
+	}
+	if err := s.Write(d, Accounts, account, Activations, activationFileName(r.ID)); err != nil {
+		return err
+	}
+	change := ClaimChange{Kind: ActivationChange, Name: account, PublicKey: r.Target, ID: r.ID}
+	if ac, err := jwt.DecodeActivationClaims(r.Token); err == nil {
+		change.Issuer = ac.Issuer
+	}
+	s.RecordChange(change)
+	return nil
+}
+
+// ReadActivation returns the activation record for the specified JTI or nil if not found
+func (s *Store) ReadActivation(account string, jti string) (*ActivationRecord, error) {
+	if err := ValidateJTI(jti); err != nil {
+		return nil, err
+	}
+	if !s.Has(Accounts, account, Activations, activationFileName(jti)) {
+		return nil, nil
+	}
+	var r ActivationRecord
+	if err := s.loadJson(&r, Accounts, account, Activations, activationFileName(jti)); err != nil {
+		return nil, fmt.Errorf("error loading activation %q: %v", jti, err)
+	}
+	return &r, nil
+}
+
+// ListActivations returns all the activations recorded for an account sorted by expiration
+func (s *Store) ListActivations(account string) ([]*ActivationRecord, error) {
+	var records []*ActivationRecord
+	if !s.Has(Accounts, account, Activations) {
+		return records, nil
+	}
+	infos, err := s.List(Accounts, account, Activations)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range infos {
+		if v.IsDir() || !strings.HasSuffix(v.Name(), activationExtension) {
+			continue
+		}
+		jti := strings.TrimSuffix(v.Name(), activationExtension)
+		if ValidateJTI(jti) != nil {
+			continue
+		}
+		r, err := s.ReadActivation(account, jti)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			records = append(records, r)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i].Expires, records[j].Expires
+		// activations that never expire go last
+		if a == 0 || b == 0 {
+			return a != 0
+		}
+		return a < b
+	})
+	return records, nil
+}
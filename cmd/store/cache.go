@@ -0,0 +1,61 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+const Cache = "cache"
+
+// CachedActivation is a local copy of an activation token retrieved from an url
+type CachedActivation struct {
+	URL     string `json:"url"`
+	ETag    string `json:"etag,omitempty"`
+	ID      string `json:"jti"`
+	Token   string `json:"token"`
+	Fetched int64  `json:"fetched"`
+}
+
+func cachedActivationName(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(h[:]) + ".json"
+}
+
+// CacheActivation stores a remote activation in the store's cache
+func (s *Store) CacheActivation(c *CachedActivation) error {
+	d, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing cached activation %q: %v", c.URL, err)
+	}
+	return s.Write(d, Cache, Activations, cachedActivationName(c.URL))
+}
+
+// ReadCachedActivation returns the cached activation for the url or nil if not cached
+func (s *Store) ReadCachedActivation(url string) (*CachedActivation, error) {
+	fn := cachedActivationName(url)
+	if !s.Has(Cache, Activations, fn) {
+		return nil, nil
+	}
+	var c CachedActivation
+	if err := s.loadJson(&c, Cache, Activations, fn); err != nil {
+		return nil, fmt.Errorf("error loading cached activation %q: %v", url, err)
+	}
+	return &c, nil
+}
@@ -85,6 +85,14 @@ func (k *KeyStore) keypath(name string, kp nkeys.KeyPair, parent string) (string
 	if err != nil {
 		return "", err
 	}
+	if err := ValidateName(kt.String(), name); err != nil {
+		return "", err
+	}
+	if parent != "" {
+		if err := ValidateName("parent", parent); err != nil {
+			return "", err
+		}
+	}
 	switch kt {
 	case nkeys.PrefixByteOperator:
 		return filepath.Join(GetKeysDir(), k.Env, k.keyName(name)), nil
@@ -107,6 +115,61 @@ func (k *KeyStore) keypath(name string, kp nkeys.KeyPair, parent string) (string
 	}
 }
 
+// Dir returns the directory with the keys of the environment
+func (k *KeyStore) Dir() string {
+	return filepath.Join(GetKeysDir(), k.Env)
+}
+
+// PrunedKeysDir is the directory in the keys directory where pruned seeds are
+// kept, environments are named after operators which cannot start with '.'
+const PrunedKeysDir = ".pruned"
+
+// PrunedDir returns the directory where the pruned seeds of the environment are kept
+func (k *KeyStore) PrunedDir() string {
+	return filepath.Join(GetKeysDir(), PrunedKeysDir, k.Env)
+}
+
+// KeyPath returns the path of the key of an entity, users and servers are
+// in the directory of their account or cluster parent
+func (k *KeyStore) KeyPath(kind nkeys.PrefixByte, parent string, name string) string {
+	switch kind {
+	case nkeys.PrefixByteOperator:
+		return filepath.Join(k.Dir(), k.keyName(name))
+	case nkeys.PrefixByteAccount:
+		return filepath.Join(k.Dir(), Accounts, name, k.keyName(name))
+	case nkeys.PrefixByteUser:
+		return filepath.Join(k.Dir(), Accounts, parent, Users, k.keyName(name))
+	case nkeys.PrefixByteCluster:
+		return filepath.Join(k.Dir(), Clusters, name, k.keyName(name))
+	case nkeys.PrefixByteServer:
+		return filepath.Join(k.Dir(), Clusters, parent, Servers, k.keyName(name))
+	default:
+		return ""
+	}
+}
+
+// ListKeys returns the paths of the key files of the environment
+func (k *KeyStore) ListKeys() ([]string, error) {
+	var keys []string
+	dir := k.Dir()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	err := filepath.Walk(dir, func(fp string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(fp) == "."+NKeyExtension {
+			keys = append(keys, fp)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing keys in %q: %v", dir, err)
+	}
+	return keys, nil
+}
+
 func (k *KeyStore) GetOperatorKey(name string) (nkeys.KeyPair, error) {
 	return k.Read(filepath.Join(GetKeysDir(), k.Env, k.keyName(name)))
 }
@@ -213,6 +276,51 @@ func (k *KeyStore) Store(keyname string, kp nkeys.KeyPair, parent string) (strin
 	return k.store(keyname, fp, kp)
 }
 
+// Rename moves the key of an entity to a new name. Account and cluster keys move
+// with their directory, so the keys of their users and servers follow. Entities
+// without a stored key are ignored.
+func (k *KeyStore) Rename(kind nkeys.PrefixByte, parent string, from string, to string) error {
+	if err := ValidateName(kind.String(), to); err != nil {
+		return err
+	}
+	var dir string
+	switch kind {
+	case nkeys.PrefixByteAccount:
+		dir = filepath.Join(GetKeysDir(), k.Env, Accounts)
+	case nkeys.PrefixByteCluster:
+		dir = filepath.Join(GetKeysDir(), k.Env, Clusters)
+	case nkeys.PrefixByteUser:
+		dir = filepath.Join(GetKeysDir(), k.Env, Accounts, parent, Users)
+	case nkeys.PrefixByteServer:
+		dir = filepath.Join(GetKeysDir(), k.Env, Clusters, parent, Servers)
+	default:
+		return fmt.Errorf("unsupported key rename for %s", kind.String())
+	}
+
+	if kind == nkeys.PrefixByteAccount || kind == nkeys.PrefixByteCluster {
+		if _, err := os.Stat(filepath.Join(dir, from)); os.IsNotExist(err) {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, to)); err == nil {
+			return fmt.Errorf("key directory %q already exists", filepath.Join(dir, to))
+		}
+		if err := os.Rename(filepath.Join(dir, from), filepath.Join(dir, to)); err != nil {
+			return err
+		}
+		dir = filepath.Join(dir, to)
+	}
+
+	fp := filepath.Join(dir, k.keyName(from))
+	if _, err := os.Stat(fp); os.IsNotExist(err) {
+		return nil
+	}
+	np := filepath.Join(dir, k.keyName(to))
+	if _, err := os.Stat(np); err == nil {
+		return fmt.Errorf("key %q already exists", np)
+	}
+	return os.Rename(fp, np)
+}
+
 func (k *KeyStore) Read(path string) (nkeys.KeyPair, error) {
 	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LockFile is the name of the lock file in the store directory
+const LockFile = ".nsc.lock"
+
+// ErrStoreLocked is returned by LockDir when another process holds the lock
+// for longer than LockTimeout
+var ErrStoreLocked = errors.New("store is locked by another nsc process")
+
+// LockTimeout is how long LockDir retries a lock held by another process
+var LockTimeout = 10 * time.Second
+
+const lockRetryInterval = 50 * time.Millisecond
+
+type dirLock struct {
+	f     *os.File
+	count int
+}
+
+// the locks held by this process, the file locks don't exclude
+// other file descriptors in the same process
+var dirLocks = struct {
+	sync.Mutex
+	m map[string]*dirLock
+}{m: make(map[string]*dirLock)}
+
+// LockDir takes an advisory exclusive lock on the store directory, retrying
+// for up to LockTimeout while another process holds it. Locks are reentrant
+// within a process and must be released with UnlockDir.
+func (s *Store) LockDir() error {
+	fp, err := filepath.Abs(filepath.Join(s.Dir, LockFile))
+	if err != nil {
+		return err
+	}
+	dirLocks.Lock()
+	defer dirLocks.Unlock()
+	if l, ok := dirLocks.m[fp]; ok {
+		l.count++
+		return nil
+	}
+	f, err := os.OpenFile(fp, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening lock %q: %v", fp, err)
+	}
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("error locking %q: %v", fp, err)
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return ErrStoreLocked
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	dirLocks.m[fp] = &dirLock{f: f, count: 1}
+	return nil
+}
+
+// UnlockDir releases the lock taken by LockDir
+func (s *Store) UnlockDir() error {
+	fp, err := filepath.Abs(filepath.Join(s.Dir, LockFile))
+	if err != nil {
+		return err
+	}
+	dirLocks.Lock()
+	defer dirLocks.Unlock()
+	l, ok := dirLocks.m[fp]
+	if !ok {
+		return fmt.Errorf("%q is not locked", fp)
+	}
+	l.count--
+	if l.count > 0 {
+		return nil
+	}
+	delete(dirLocks.m, fp)
+	err = unlockFile(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("error unlocking %q: %v", fp, err)
+	}
+	return nil
+}
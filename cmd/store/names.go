@@ -0,0 +1,154 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MinKeyPrefix is the shortest public key prefix that resolves an entity
+const MinKeyPrefix = 4
+
+// ValidateName checks that a name can be used as a file name in the store
+// and the keystore without escaping their directories
+func ValidateName(kind string, name string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid %s name %q - %s", kind, name, reason)
+	}
+	switch {
+	case name == "":
+		return fmt.Errorf("%s name is required", kind)
+	case strings.TrimSpace(name) != name:
+		return invalid("names cannot start or end with spaces")
+	case strings.HasPrefix(name, "."):
+		return invalid("names cannot start with '.'")
+	case len(name) > 255:
+		return invalid("names are limited to 255 characters")
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) || strings.ContainsRune("/\\:*?\"<>|", r) {
+			return invalid(fmt.Sprintf("names cannot contain %q", r))
+		}
+	}
+	return nil
+}
+
+// isKeyPrefix returns true if v could be a prefix of a public key of the kind
+func isKeyPrefix(prefix byte, v string) bool {
+	if len(v) < MinKeyPrefix || v[0] != prefix {
+		return false
+	}
+	for _, r := range v {
+		if !(r >= 'A' && r <= 'Z' || r >= '2' && r <= '7') {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve returns the name if it is one of the names, otherwise the name of
+// the entity with a public key that starts with it
+func resolve(kind string, prefix byte, v string, names []string, pubkey func(name string) (string, error)) (string, error) {
+	for _, n := range names {
+		if n == v {
+			return v, nil
+		}
+	}
+	if !isKeyPrefix(prefix, v) {
+		return v, nil
+	}
+	var matches []string
+	for _, n := range names {
+		pk, err := pubkey(n)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(pk, v) {
+			matches = append(matches, n)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return v, nil
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%q matches the public keys of %ss %s - use a longer prefix", v, kind, strings.Join(matches, ", "))
+	}
+}
+
+// ResolveAccount returns the name of the account named v, or of the account
+// with a public key that starts with v. Unknown values are returned as is.
+func (s *Store) ResolveAccount(v string) (string, error) {
+	names, err := s.ListSubContainers(Accounts)
+	if err != nil {
+		return "", err
+	}
+	return resolve("account", 'A', v, names, func(n string) (string, error) {
+		c, err := s.ReadAccountClaim(n)
+		if err != nil || c == nil {
+			return "", err
+		}
+		return c.Subject, nil
+	})
+}
+
+// ResolveUser returns the name of the user in the account named v, or with a public key that starts with v
+func (s *Store) ResolveUser(account string, v string) (string, error) {
+	names, err := s.ListEntries(Accounts, account, Users)
+	if err != nil {
+		return "", err
+	}
+	return resolve("user", 'U', v, names, func(n string) (string, error) {
+		c, err := s.ReadUserClaim(account, n)
+		if err != nil || c == nil {
+			return "", err
+		}
+		return c.Subject, nil
+	})
+}
+
+// ResolveCluster returns the name of the cluster named v, or with a public key that starts with v
+func (s *Store) ResolveCluster(v string) (string, error) {
+	names, err := s.ListSubContainers(Clusters)
+	if err != nil {
+		return "", err
+	}
+	return resolve("cluster", 'C', v, names, func(n string) (string, error) {
+		c, err := s.ReadClusterClaim(n)
+		if err != nil || c == nil {
+			return "", err
+		}
+		return c.Subject, nil
+	})
+}
+
+// ResolveServer returns the name of the server in the cluster named v, or with a public key that starts with v
+func (s *Store) ResolveServer(cluster string, v string) (string, error) {
+	names, err := s.ListEntries(Clusters, cluster, Servers)
+	if err != nil {
+		return "", err
+	}
+	return resolve("server", 'N', v, names, func(n string) (string, error) {
+		c, err := s.ReadServerClaim(cluster, n)
+		if err != nil || c == nil {
+			return "", err
+		}
+		return c.Subject, nil
+	})
+}
@@ -16,12 +16,15 @@
 package store
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -46,10 +49,24 @@ type Store struct {
 	Dir            string
 	Info           Info
 	DefaultAccount string
+	changes        []ClaimChange
+	reads          map[string][sha256.Size]byte
+}
+
+// ClaimChange describes a claim written to the store, PrevID is the
+// JWT ID of the claim it replaced
+type ClaimChange struct {
+	Kind      string
+	Name      string
+	PublicKey string
+	Issuer    string
+	PrevID    string
+	ID        string
 }
 
 type Info struct {
 	Managed         bool   `json:"managed"`
+	OperatorKey     string `json:"operator_key,omitempty"`
 	EntityName      string `json:"name"`
 	EnvironmentName string `json:"env"`
 	Kind            string `json:"kind"`
@@ -154,8 +171,20 @@ func (s *Store) createOperatorToken(operator *NamedKey) (string, error) {
 	return token, nil
 }
 
-// LoadStore loads a store from the specified directory path.
+// LoadStore loads the store in the directory, stores at another version
+// need to be upgraded first
 func LoadStore(dir string) (*Store, error) {
+	s, err := openStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckVersion(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func openStore(dir string) (*Store, error) {
 	sf := filepath.Join(dir, NSCFile)
 	if _, err := os.Stat(sf); os.IsNotExist(err) {
 		return nil, fmt.Errorf("%q is not a valid configuration directory", sf)
@@ -173,6 +202,26 @@ func (s *Store) IsManaged() bool {
 	return s.Info.Managed
 }
 
+// SetOperatorKey records the public key of the external operator that signs
+// the accounts of a managed store
+func (s *Store) SetOperatorKey(pub string) error {
+	if !s.IsManaged() {
+		return errors.New("only managed stores have an external operator")
+	}
+	if !nkeys.IsValidPublicOperatorKey(pub) {
+		return fmt.Errorf("%q is not an operator public key", pub)
+	}
+	s.Info.OperatorKey = pub
+	d, err := json.Marshal(s.Info)
+	if err != nil {
+		return fmt.Errorf("error serializing .nsc: %v", err)
+	}
+	if err := s.Write(d, NSCFile); err != nil {
+		return fmt.Errorf("error writing .nsc in %q: %v", s.Dir, err)
+	}
+	return nil
+}
+
 func (s *Store) resolve(name ...string) string {
 	return filepath.Join(s.Dir, filepath.Join(name...))
 }
@@ -199,9 +248,46 @@ func (s *Store) Read(name ...string) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error reading %q: %v", fp, err)
 	}
+	if s.reads != nil {
+		s.reads[fp] = sha256.Sum256(d)
+	}
 	return d, nil
 }
 
+// WatchReads records the contents of the files read from the store
+// from now on, so ChangedReads can tell if they were modified since
+func (s *Store) WatchReads() {
+	s.Lock()
+	defer s.Unlock()
+	s.reads = make(map[string][sha256.Size]byte)
+}
+
+// ChangedReads stops watching reads and returns the store relative paths
+// of the files read since WatchReads that were modified or removed
+func (s *Store) ChangedReads() ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	reads := s.reads
+	s.reads = nil
+
+	var changed []string
+	for fp, sum := range reads {
+		d, err := ioutil.ReadFile(fp)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading %q: %v", fp, err)
+		}
+		if now := sha256.Sum256(d); err != nil || !bytes.Equal(now[:], sum[:]) {
+			rp, rerr := filepath.Rel(s.Dir, fp)
+			if rerr != nil {
+				rp = fp
+			}
+			changed = append(changed, rp)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
 // Write writes the specified file name or subpath in the store
 func (s *Store) Write(data []byte, name ...string) error {
 	s.Lock()
@@ -213,7 +299,41 @@ func (s *Store) Write(data []byte, name ...string) error {
 	if err := os.MkdirAll(dp, 0700); err != nil {
 		return err
 	}
-	return ioutil.WriteFile(fp, data, 0600)
+	if err := writeAtomic(fp, data); err != nil {
+		return err
+	}
+	if _, ok := s.reads[fp]; ok {
+		s.reads[fp] = sha256.Sum256(data)
+	}
+	return nil
+}
+
+// writeAtomic writes to a temporary file in the same directory that is
+// renamed into place, so readers never see a partial file
+func writeAtomic(fp string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(fp), fmt.Sprintf(".%s.tmp", filepath.Base(fp)))
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp, fp)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing %q: %v", fp, err)
+	}
+	return nil
 }
 
 func (s *Store) List(path ...string) ([]os.FileInfo, error) {
@@ -232,6 +352,18 @@ func (s *Store) Delete(name ...string) error {
 	return os.Remove(fp)
 }
 
+// Rename moves the specified file name or subpath to a new name in the same directory
+func (s *Store) Rename(newName string, name ...string) error {
+	s.Lock()
+	defer s.Unlock()
+	fp := s.resolve(name...)
+	np := filepath.Join(filepath.Dir(fp), newName)
+	if s.has(np) {
+		return fmt.Errorf("%q already exists", np)
+	}
+	return os.Rename(fp, np)
+}
+
 func (s *Store) ListSubContainers(name ...string) ([]string, error) {
 	var containers []string
 	fp := filepath.Join(name...)
@@ -277,6 +409,9 @@ func (s *Store) StoreClaim(data []byte) error {
 	if gc.Name == "" {
 		return errors.New("jwt claim doesn't have a name")
 	}
+	if err := ValidateName(string(gc.Type), gc.Name); err != nil {
+		return err
+	}
 	var path string
 	switch gc.Type {
 	case jwt.AccountClaim:
@@ -339,7 +474,36 @@ func (s *Store) StoreClaim(data []byte) error {
 		return fmt.Errorf("unsuported store claim type: %s", gc.Type)
 	}
 
-	return s.Write(data, path)
+	change := ClaimChange{Kind: string(gc.Type), Name: gc.Name, PublicKey: gc.Subject, Issuer: gc.Issuer, ID: gc.ID}
+	prev, err := s.LoadClaim(path)
+	if err != nil {
+		return err
+	}
+	if prev != nil {
+		change.PrevID = prev.ID
+	}
+	if err := s.Write(data, path); err != nil {
+		return err
+	}
+	s.RecordChange(change)
+	return nil
+}
+
+// RecordChange adds a change to the ones returned by TakeChanges, claims are
+// recorded by StoreClaim, other changes like seeds are recorded by the caller
+func (s *Store) RecordChange(c ClaimChange) {
+	s.Lock()
+	s.changes = append(s.changes, c)
+	s.Unlock()
+}
+
+// TakeChanges returns the claims written since the last call
+func (s *Store) TakeChanges() []ClaimChange {
+	s.Lock()
+	defer s.Unlock()
+	c := s.changes
+	s.changes = nil
+	return c
 }
 
 func (s *Store) GetName() string {
@@ -418,6 +582,24 @@ func (s *Store) ReadAccountClaim(name string) (*jwt.AccountClaims, error) {
 	return nil, nil
 }
 
+// FindAccount returns the name of the account with the specified public key or "" if not found
+func (s *Store) FindAccount(pubkey string) (string, error) {
+	accounts, err := s.ListSubContainers(Accounts)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range accounts {
+		c, err := s.LoadClaim(Accounts, a, JwtName(a))
+		if err != nil {
+			return "", err
+		}
+		if c != nil && c.Subject == pubkey {
+			return a, nil
+		}
+	}
+	return "", nil
+}
+
 func (s *Store) ReadUserClaim(accountName string, name string) (*jwt.UserClaims, error) {
 	if s.Has(Accounts, accountName, Users, JwtName(name)) {
 		d, err := s.Read(Accounts, accountName, Users, JwtName(name))
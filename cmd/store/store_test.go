@@ -18,8 +18,10 @@ package store
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/nats-io/jwt"
 	"github.com/nats-io/nkeys"
@@ -204,6 +206,39 @@ func TestStoreAccount(t *testing.T) {
 	require.Equal(t, gc.Name, "foo")
 }
 
+func TestStoreClaimChanges(t *testing.T) {
+	_, _, kp := CreateOperatorKey(t)
+	_, apub, _ := CreateAccountKey(t)
+	s := CreateTestStoreForOperator(t, "x", kp)
+	opk, err := kp.PublicKey()
+	require.NoError(t, err)
+	changes := s.TakeChanges()
+	require.Len(t, changes, 1)
+	require.Equal(t, string(jwt.OperatorClaim), changes[0].Kind)
+
+	c := jwt.NewAccountClaims(apub)
+	c.Name = "foo"
+	cd, err := c.Encode(kp)
+	require.NoError(t, err)
+	require.NoError(t, s.StoreClaim([]byte(cd)))
+	first, err := jwt.DecodeAccountClaims(cd)
+	require.NoError(t, err)
+
+	c.Tags.Add("bar")
+	cd, err = c.Encode(kp)
+	require.NoError(t, err)
+	require.NoError(t, s.StoreClaim([]byte(cd)))
+	second, err := jwt.DecodeAccountClaims(cd)
+	require.NoError(t, err)
+
+	changes = s.TakeChanges()
+	require.Len(t, changes, 2)
+	require.Equal(t, ClaimChange{Kind: string(jwt.AccountClaim), Name: "foo", PublicKey: apub, Issuer: opk, ID: first.ID}, changes[0])
+	require.Equal(t, first.ID, changes[1].PrevID)
+	require.Equal(t, second.ID, changes[1].ID)
+	require.Empty(t, s.TakeChanges())
+}
+
 func TestStoreUser(t *testing.T) {
 	_, _, kp := CreateOperatorKey(t)
 	_, apub, akp := CreateAccountKey(t)
@@ -254,3 +289,91 @@ func TestStore_ListSubContainers(t *testing.T) {
 	require.Len(t, v, 1)
 	require.Equal(t, "bar", v[0])
 }
+
+func TestLockDirReentrant(t *testing.T) {
+	s := CreateTestStore(t, "x")
+	require.NoError(t, s.LockDir())
+	require.NoError(t, s.LockDir())
+	require.NoError(t, s.UnlockDir())
+	require.NoError(t, s.UnlockDir())
+	require.Error(t, s.UnlockDir())
+	require.FileExists(t, filepath.Join(s.Dir, LockFile))
+}
+
+func TestLockDirHeldElsewhere(t *testing.T) {
+	s := CreateTestStore(t, "x")
+	f, err := os.OpenFile(filepath.Join(s.Dir, LockFile), os.O_CREATE|os.O_RDWR, 0600)
+	require.NoError(t, err)
+	defer f.Close()
+	ok, err := tryLockFile(f)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	timeout := LockTimeout
+	LockTimeout = 100 * time.Millisecond
+	defer func() { LockTimeout = timeout }()
+	require.Equal(t, ErrStoreLocked, s.LockDir())
+
+	require.NoError(t, unlockFile(f))
+	require.NoError(t, s.LockDir())
+	require.NoError(t, s.UnlockDir())
+}
+
+func TestWriteReplacesFile(t *testing.T) {
+	s := CreateTestStore(t, "x")
+	require.NoError(t, s.Write([]byte("one"), "dir", "f.txt"))
+	require.NoError(t, s.Write([]byte("two"), "dir", "f.txt"))
+	d, err := s.Read("dir", "f.txt")
+	require.NoError(t, err)
+	require.Equal(t, "two", string(d))
+
+	// no temporary files are left behind
+	infos, err := s.List("dir")
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, os.FileMode(0600), infos[0].Mode().Perm())
+}
+
+func TestSetOperatorKey(t *testing.T) {
+	s := CreateTestStore(t, "x")
+	_, opub, _ := CreateOperatorKey(t)
+	require.Error(t, s.SetOperatorKey(opub))
+
+	m, err := CreateStore("m", MakeTempDir(t), &NamedKey{Name: "m"})
+	require.NoError(t, err)
+	require.True(t, m.IsManaged())
+	_, apub, _ := CreateAccountKey(t)
+	require.Error(t, m.SetOperatorKey(apub))
+	require.NoError(t, m.SetOperatorKey(opub))
+
+	v, err := LoadStore(m.Dir)
+	require.NoError(t, err)
+	require.Equal(t, opub, v.Info.OperatorKey)
+}
+
+func TestChangedReads(t *testing.T) {
+	s := CreateTestStore(t, "test-account")
+	require.NoError(t, s.Write([]byte("foo"), Users, "foo"))
+	require.NoError(t, s.Write([]byte("bar"), Users, "bar"))
+	require.NoError(t, s.Write([]byte("baz"), Users, "baz"))
+
+	s.WatchReads()
+	for _, n := range []string{"foo", "bar", "baz"} {
+		_, err := s.Read(Users, n)
+		require.NoError(t, err)
+	}
+	// writes by the store itself are not changes
+	require.NoError(t, s.Write([]byte("baz2"), Users, "baz"))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(s.Dir, Users, "foo"), []byte("foo2"), 0600))
+	require.NoError(t, os.Remove(filepath.Join(s.Dir, Users, "bar")))
+
+	changed, err := s.ChangedReads()
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(Users, "bar"), filepath.Join(Users, "foo")}, changed)
+
+	// no longer watching
+	changed, err = s.ChangedReads()
+	require.NoError(t, err)
+	require.Empty(t, changed)
+}
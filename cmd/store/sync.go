@@ -0,0 +1,68 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+const Sync = "sync"
+
+// SyncedAccount is the version of an account JWT last known to match the resolver
+type SyncedAccount struct {
+	ID       string `json:"jti"`
+	IssuedAt int64  `json:"iat"`
+	Synced   int64  `json:"synced"`
+}
+
+// SyncState tracks the account JWTs synchronized with a resolver, keyed by account public key
+type SyncState struct {
+	URL      string                    `json:"url"`
+	Accounts map[string]*SyncedAccount `json:"accounts"`
+}
+
+func syncStateName(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(h[:]) + ".json"
+}
+
+// ReadSyncState returns the sync state for the resolver url, an empty state is returned if
+// the resolver was never synced
+func (s *Store) ReadSyncState(url string) (*SyncState, error) {
+	state := &SyncState{URL: url}
+	fn := syncStateName(url)
+	if s.Has(Sync, fn) {
+		if err := s.loadJson(state, Sync, fn); err != nil {
+			return nil, fmt.Errorf("error loading sync state for %q: %v", url, err)
+		}
+	}
+	if state.Accounts == nil {
+		state.Accounts = make(map[string]*SyncedAccount)
+	}
+	return state, nil
+}
+
+// StoreSyncState saves the sync state for a resolver
+func (s *Store) StoreSyncState(state *SyncState) error {
+	d, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing sync state for %q: %v", state.URL, err)
+	}
+	return s.Write(d, Sync, syncStateName(state.URL))
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Migration upgrades the layout of a store to Version from the version before it
+type Migration struct {
+	Version     int
+	Description string
+	Migrate     func(s *Store) error
+}
+
+// migrations are the ordered steps that upgrade a store to the current Version.
+// A change to the layout of the store bumps Version and registers the
+// migration that converts existing stores.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "record the store version in .nsc",
+		Migrate:     func(s *Store) error { return nil },
+	},
+}
+
+// RegisterMigration adds a migration after the registered ones
+func RegisterMigration(m Migration) error {
+	if n := len(migrations); n > 0 && m.Version <= migrations[n-1].Version {
+		return fmt.Errorf("migration to version %d must follow version %d", m.Version, migrations[n-1].Version)
+	}
+	if m.Migrate == nil {
+		return fmt.Errorf("migration to version %d doesn't have a migrate function", m.Version)
+	}
+	migrations = append(migrations, m)
+	return nil
+}
+
+// ParseVersion parses a store version, stores that predate versioning are version 0
+func ParseVersion(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid store version %q", v)
+	}
+	return n, nil
+}
+
+// CheckVersion returns an error if the store isn't at the version of this nsc
+func (s *Store) CheckVersion() error {
+	v, err := ParseVersion(s.Info.Version)
+	if err != nil {
+		return err
+	}
+	current, _ := ParseVersion(Version)
+	switch {
+	case v > current:
+		return fmt.Errorf("store %q is version %d, which is newer than version %d supported by this nsc - update nsc", s.Dir, v, current)
+	case v < current:
+		return fmt.Errorf("store %q is version %d and needs to be upgraded to version %d - run 'nsc upgrade-store'", s.Dir, v, current)
+	}
+	return nil
+}
+
+// PendingMigrations returns the migrations that upgrade the store
+func (s *Store) PendingMigrations() ([]Migration, error) {
+	v, err := ParseVersion(s.Info.Version)
+	if err != nil {
+		return nil, err
+	}
+	current, _ := ParseVersion(Version)
+	if v > current {
+		return nil, s.CheckVersion()
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > v && m.Version <= current {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// OpenStoreForUpgrade loads a store without checking its version
+func OpenStoreForUpgrade(dir string) (*Store, error) {
+	return openStore(dir)
+}
+
+// Upgrade copies the store to the backup directory and applies the pending
+// migrations, recording the version after each step so that an interrupted
+// upgrade resumes where it stopped
+func (s *Store) Upgrade(backupDir string) ([]Migration, error) {
+	if err := s.LockDir(); err != nil {
+		return nil, err
+	}
+	defer s.UnlockDir()
+
+	pending, err := s.PendingMigrations()
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+	if err := copyDir(s.Dir, backupDir); err != nil {
+		return nil, fmt.Errorf("error backing up %q to %q: %v", s.Dir, backupDir, err)
+	}
+	for i, m := range pending {
+		if err := m.Migrate(s); err != nil {
+			return pending[:i], fmt.Errorf("error upgrading %q to version %d: %v", s.Dir, m.Version, err)
+		}
+		s.Info.Version = strconv.Itoa(m.Version)
+		d, err := json.Marshal(s.Info)
+		if err != nil {
+			return pending[:i], fmt.Errorf("error serializing .nsc: %v", err)
+		}
+		if err := s.Write(d, NSCFile); err != nil {
+			return pending[:i], fmt.Errorf("error writing .nsc in %q: %v", s.Dir, err)
+		}
+	}
+	return pending, nil
+}
+
+// copyDir copies the files in src to dst, which must not exist
+func copyDir(src string, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%q already exists", dst)
+	}
+	return filepath.Walk(src, func(fp string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, fp)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		if rel == LockFile {
+			return nil
+		}
+		return copyFile(fp, target)
+	})
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
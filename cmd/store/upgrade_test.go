@@ -0,0 +1,117 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setVersion(t *testing.T, s *Store, v string) {
+	s.Info.Version = v
+	d, err := json.Marshal(s.Info)
+	require.NoError(t, err)
+	require.NoError(t, s.Write(d, NSCFile))
+}
+
+func TestVersionIsLastMigration(t *testing.T) {
+	require.NotEmpty(t, migrations)
+	require.Equal(t, Version, strconv.Itoa(migrations[len(migrations)-1].Version))
+}
+
+func TestLoadStoreChecksVersion(t *testing.T) {
+	s := CreateTestStore(t, "x")
+	_, err := LoadStore(s.Dir)
+	require.NoError(t, err)
+
+	setVersion(t, s, "")
+	_, err = LoadStore(s.Dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "nsc upgrade-store")
+
+	setVersion(t, s, "1000")
+	_, err = LoadStore(s.Dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "update nsc")
+	_, err = OpenStoreForUpgrade(s.Dir)
+	require.NoError(t, err)
+
+	setVersion(t, s, "one")
+	_, err = LoadStore(s.Dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid store version")
+}
+
+func TestUpgrade(t *testing.T) {
+	s := CreateTestStore(t, "x")
+	setVersion(t, s, "")
+
+	u, err := OpenStoreForUpgrade(s.Dir)
+	require.NoError(t, err)
+	pending, err := u.PendingMigrations()
+	require.NoError(t, err)
+	require.Len(t, pending, len(migrations))
+
+	backup := filepath.Join(MakeTempDir(t), "backup")
+	applied, err := u.Upgrade(backup)
+	require.NoError(t, err)
+	require.Len(t, applied, len(pending))
+	require.FileExists(t, filepath.Join(backup, NSCFile))
+	require.FileExists(t, filepath.Join(backup, "x.jwt"))
+
+	// the backup is the store before the upgrade
+	b, err := OpenStoreForUpgrade(backup)
+	require.NoError(t, err)
+	require.Equal(t, "", b.Info.Version)
+
+	s, err = LoadStore(s.Dir)
+	require.NoError(t, err)
+	require.Equal(t, Version, s.Info.Version)
+	pending, err = s.PendingMigrations()
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestUpgradeStopsOnError(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = []Migration{{Version: 1, Description: "fails", Migrate: func(s *Store) error {
+		return errors.New("failed")
+	}}}
+
+	s := CreateTestStore(t, "x")
+	setVersion(t, s, "")
+	_, err := s.Upgrade(filepath.Join(MakeTempDir(t), "backup"))
+	require.Error(t, err)
+	s, err = OpenStoreForUpgrade(s.Dir)
+	require.NoError(t, err)
+	require.Equal(t, "", s.Info.Version)
+}
+
+func TestRegisterMigration(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+
+	require.Error(t, RegisterMigration(Migration{Version: 1, Migrate: func(s *Store) error { return nil }}))
+	require.Error(t, RegisterMigration(Migration{Version: 2}))
+	require.NoError(t, RegisterMigration(Migration{Version: 2, Migrate: func(s *Store) error { return nil }}))
+	require.Len(t, migrations, len(saved)+1)
+}
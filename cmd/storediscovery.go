@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nats-io/nsc/cmd/store"
+)
+
+// storeSource describes where the store settings in effect came from, it is reported by env
+var storeSource = defaultStoreSource
+
+const defaultStoreSource = "global configuration"
+
+// FindLocalStore walks up from dir looking for a directory with a store marker file,
+// returning the directory of the store or "" if none was found
+func FindLocalStore(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		fi, err := os.Stat(filepath.Join(dir, store.NSCFile))
+		if err == nil && fi.Mode().IsRegular() {
+			if _, err := store.LoadStore(dir); err == nil {
+				return dir, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// DiscoverLocalStore uses the store containing the current directory, if any, instead
+// of the store in the tool configuration. The discovered store is not saved.
+func (d *ToolConfig) DiscoverLocalStore() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	dir, err := FindLocalStore(wd)
+	if err != nil || dir == "" {
+		return err
+	}
+	root, operator := filepath.Dir(dir), filepath.Base(dir)
+	reason := fmt.Sprintf("local store - found %s in %q", store.NSCFile, dir)
+	if d.StoreRoot == root && d.Operator == operator {
+		storeSource = reason
+		return nil
+	}
+
+	c := ContextConfig{StoreRoot: root, Operator: operator}
+	s, err := c.LoadStore(operator)
+	if err != nil {
+		return err
+	}
+	if accounts, err := s.ListSubContainers(store.Accounts); err == nil && len(accounts) == 1 {
+		c.Account = accounts[0]
+	}
+	if clusters, err := s.ListSubContainers(store.Clusters); err == nil && len(clusters) == 1 {
+		c.Cluster = clusters[0]
+	}
+	d.override(c)
+	storeSource = reason
+	return nil
+}
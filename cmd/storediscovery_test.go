@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FindLocalStore(t *testing.T) {
+	ts := NewTestStore(t, "local")
+	defer ts.Done(t)
+
+	ts.AddAccount(t, "A")
+	dir, err := FindLocalStore(filepath.Join(ts.Store.Dir, store.Accounts, "A"))
+	require.NoError(t, err)
+	require.Equal(t, ts.Store.Dir, dir)
+
+	dir, err = FindLocalStore(ts.Store.Dir)
+	require.NoError(t, err)
+	require.Equal(t, ts.Store.Dir, dir)
+
+	// the tool home is also named .nsc but it is a directory
+	d := MakeTempDir(t)
+	require.NoError(t, os.Mkdir(filepath.Join(d, store.NSCFile), 0700))
+	dir, err = FindLocalStore(d)
+	require.NoError(t, err)
+	require.Empty(t, dir)
+}
+
+func Test_DiscoverLocalStore(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+	defer configHome(t)()
+
+	ps := ts.AddOperator(t, "prod")
+	require.NoError(t, ps.Write([]byte("x"), store.Accounts, "B", "B.jwt"))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(wd)
+	require.NoError(t, os.Chdir(filepath.Join(ps.Dir, store.Accounts)))
+
+	conf := GetConfig()
+	require.Equal(t, "dev", conf.Operator)
+	require.NoError(t, conf.DiscoverLocalStore())
+	require.Equal(t, "prod", conf.Operator)
+	require.Equal(t, "B", conf.Account)
+	require.Contains(t, storeSource, "local store - found .nsc in")
+
+	_, stderr, err := ExecuteCmd(createEnvCmd())
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "Default Operator prod")
+	require.Contains(t, stderr, "Default Operator prod local store - found .nsc in")
+
+	// the discovered store is not saved
+	require.NoError(t, conf.Save())
+	var saved ToolConfig
+	require.NoError(t, ReadJson(conf.configFile(), &saved))
+	require.Equal(t, "dev", saved.Operator)
+}
+
+func Test_EnvReportsConfigSource(t *testing.T) {
+	ts := NewTestStore(t, "dev")
+	defer ts.Done(t)
+
+	_, stderr, err := ExecuteCmd(createEnvCmd())
+	require.NoError(t, err)
+	require.Contains(t, StripTableDecorations(stderr), "Default Operator dev "+defaultStoreSource)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_StoreLockHelperProcess is run by Test_StoreLockParallelProcesses
+// as a separate nsc process
+func Test_StoreLockHelperProcess(t *testing.T) {
+	name := os.Getenv("NSC_TEST_LOCK_USER")
+	if name == "" {
+		t.Skip("run by Test_StoreLockParallelProcesses")
+	}
+	ResetConfigForTests()
+	ngsStore = nil
+	ForceStoreRoot(t, os.Getenv("NSC_TEST_LOCK_STORES"))
+	ForceOperator(t, "lock")
+
+	_, _, err := ExecuteCmd(CreateAddUserCmd(), "--account", "A", "--name", name)
+	require.NoError(t, err)
+	// editing the account reads and writes the same jwt in every process
+	_, _, err = ExecuteCmd(createEditAccount(), "--account", "A", "--tag", name)
+	require.NoError(t, err)
+}
+
+func Test_StoreLockParallelProcesses(t *testing.T) {
+	ts := NewTestStore(t, "lock")
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := exec.Command(os.Args[0], "-test.run=^Test_StoreLockHelperProcess$")
+			c.Env = append(os.Environ(),
+				fmt.Sprintf("NSC_TEST_LOCK_USER=u%d", i),
+				fmt.Sprintf("NSC_TEST_LOCK_STORES=%s", ts.GetStoresRoot()))
+			if out, err := c.CombinedOutput(); err != nil {
+				errs[i] = fmt.Errorf("process u%d: %v\n%s", i, err, out)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	ac, err := ts.Store.ReadAccountClaim("A")
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("u%d", i)
+		require.True(t, ts.Store.Has(store.Accounts, "A", store.Users, store.JwtName(name)), name)
+		require.Contains(t, ac.Tags, name)
+	}
+
+	infos, err := ts.Store.List(store.Accounts, "A")
+	require.NoError(t, err)
+	for _, fi := range infos {
+		require.True(t, fi.IsDir() || store.IsJwtName(fi.Name()), "unexpected file %q", fi.Name())
+	}
+}
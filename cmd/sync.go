@@ -0,0 +1,335 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+const (
+	SyncInSync   = "in sync"
+	SyncPushed   = "pushed"
+	SyncPulled   = "pulled"
+	SyncSkipped  = "unchanged since last sync"
+	SyncConflict = "conflict"
+	SyncMissing  = "not on resolver"
+	SyncFailed   = "failed"
+)
+
+// SyncResult is the outcome of pushing or pulling an account JWT
+type SyncResult struct {
+	Account string
+	Status  string
+	Error   string
+	Diff    []ClaimDiff
+}
+
+// ClaimDiff is a field that differs between the local and remote version of a claim
+type ClaimDiff struct {
+	Field  string
+	Local  string
+	Remote string
+}
+
+// SyncParams are the options shared by push and pull
+type SyncParams struct {
+	ClusterContextParams
+	account  string
+	force    bool
+	template string
+
+	url     string
+	state   *store.SyncState
+	claims  map[string]*jwt.AccountClaims
+	tokens  map[string]string
+	results []*SyncResult
+}
+
+func (p *SyncParams) bindFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&p.template, "account-url", "", "", "account url template of the resolver, default is the cluster's account url template")
+	cmd.Flags().StringVarP(&p.account, "account", "a", "", "only sync the named account")
+	cmd.Flags().BoolVarP(&p.force, "force", "F", false, "sync all accounts, overwriting conflicting versions")
+	p.ClusterContextParams.BindFlags(cmd)
+}
+
+func (p *SyncParams) SetDefaults(ctx ActionCtx) error {
+	if p.template == "" {
+		p.ClusterContextParams.SetDefaults(ctx)
+	}
+	return nil
+}
+
+func (p *SyncParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+// Load resolves the account url template and reads the accounts and the sync state
+func (p *SyncParams) Load(ctx ActionCtx) error {
+	s := ctx.StoreCtx().Store
+	if p.template == "" {
+		if err := p.ClusterContextParams.Validate(ctx); err != nil {
+			return fmt.Errorf("an --account-url or a cluster with an account url template is required")
+		}
+		cc, err := s.ReadClusterClaim(p.Name)
+		if err != nil {
+			return err
+		}
+		if cc == nil {
+			return fmt.Errorf("cluster %q is not in the store", p.Name)
+		}
+		if cc.AccountURL == "" {
+			return fmt.Errorf("cluster %q doesn't have an account url template", p.Name)
+		}
+		p.template = cc.AccountURL
+	}
+	p.url = p.template
+
+	var err error
+	p.state, err = s.ReadSyncState(p.url)
+	if err != nil {
+		return err
+	}
+
+	names, err := s.ListSubContainers(store.Accounts)
+	if err != nil {
+		return err
+	}
+	if p.account != "" {
+		if !s.Has(store.Accounts, p.account, store.JwtName(p.account)) {
+			return fmt.Errorf("account %q is not in the store", p.account)
+		}
+		names = []string{p.account}
+	}
+	p.claims = make(map[string]*jwt.AccountClaims)
+	p.tokens = make(map[string]string)
+	for _, n := range names {
+		d, err := s.Read(store.Accounts, n, store.JwtName(n))
+		if err != nil {
+			return err
+		}
+		ac, err := jwt.DecodeAccountClaims(string(d))
+		if err != nil {
+			return fmt.Errorf("error decoding account %q: %v", n, err)
+		}
+		p.claims[n] = ac
+		p.tokens[n] = string(d)
+	}
+	return nil
+}
+
+func (p *SyncParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *SyncParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+// names returns the loaded account names sorted
+func (p *SyncParams) names() []string {
+	var names []string
+	for n := range p.claims {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// conflicts returns an error if any of the accounts failed to sync
+func (p *SyncParams) conflicts() error {
+	count := 0
+	for _, r := range p.results {
+		if r.Status == SyncConflict || r.Status == SyncFailed {
+			count++
+		}
+	}
+	if count > 0 {
+		return fmt.Errorf("%d account(s) could not be synced", count)
+	}
+	return nil
+}
+
+// lastSync returns the version of the account last synced with the resolver or nil
+func (p *SyncParams) lastSync(ac *jwt.AccountClaims) *store.SyncedAccount {
+	return p.state.Accounts[ac.Subject]
+}
+
+func (p *SyncParams) synced(ac *jwt.AccountClaims) {
+	p.state.Accounts[ac.Subject] = &store.SyncedAccount{ID: ac.ID, IssuedAt: ac.IssuedAt, Synced: time.Now().Unix()}
+}
+
+// AccountURL expands the account url template for the specified account public key. Templates
+// containing a %s verb are formatted, otherwise the public key is appended.
+func AccountURL(template string, pubkey string) string {
+	if strings.Contains(template, "%s") {
+		return fmt.Sprintf(template, pubkey)
+	}
+	if !strings.HasSuffix(template, "/") {
+		template = template + "/"
+	}
+	return template + pubkey
+}
+
+// fetchAccount returns the account JWT from the resolver, or "" if the resolver doesn't have it
+func fetchAccount(url string) (string, *jwt.AccountClaims, error) {
+	hc := &http.Client{Timeout: 10 * time.Second}
+	resp, err := hc.Get(url)
+	if err != nil {
+		return "", nil, fmt.Errorf("error loading %q: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("error loading %q: %s", url, resp.Status)
+	}
+	d, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("error reading response from %q: %v", url, err)
+	}
+	token := strings.TrimSpace(string(d))
+	ac, err := jwt.DecodeAccountClaims(token)
+	if err != nil {
+		return "", nil, fmt.Errorf("error decoding account from %q: %v", url, err)
+	}
+	return token, ac, nil
+}
+
+// postAccount uploads the account JWT to the resolver
+func postAccount(url string, token string) error {
+	hc := &http.Client{Timeout: 10 * time.Second}
+	resp, err := hc.Post(url, "application/jwt", strings.NewReader(token))
+	if err != nil {
+		return fmt.Errorf("error posting to %q: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		d, _ := ioutil.ReadAll(resp.Body)
+		msg := strings.TrimSpace(string(d))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// DiffClaims compares the payloads of two claims field by field, ignoring the
+// fields that change every time a claim is encoded
+func DiffClaims(local jwt.Claims, remote jwt.Claims) ([]ClaimDiff, error) {
+	lm, err := flattenClaim(local)
+	if err != nil {
+		return nil, err
+	}
+	rm, err := flattenClaim(remote)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool)
+	for k := range lm {
+		keys[k] = true
+	}
+	for k := range rm {
+		keys[k] = true
+	}
+	var diffs []ClaimDiff
+	for k := range keys {
+		if k == "jti" || k == "iat" {
+			continue
+		}
+		if lm[k] != rm[k] {
+			diffs = append(diffs, ClaimDiff{Field: k, Local: lm[k], Remote: rm[k]})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Field < diffs[j].Field
+	})
+	return diffs, nil
+}
+
+func flattenClaim(c jwt.Claims) (map[string]string, error) {
+	d, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(d, &m); err != nil {
+		return nil, err
+	}
+	flat := make(map[string]string)
+	flatten("", m, flat)
+	return flat, nil
+}
+
+func flatten(prefix string, v interface{}, flat map[string]string) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, e := range vv {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flatten(key, e, flat)
+		}
+	default:
+		d, _ := json.Marshal(vv)
+		flat[prefix] = string(d)
+	}
+}
+
+// SyncSummary renders the results of a push or pull
+func SyncSummary(title string, results []*SyncResult) string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle(title)
+	table.AddHeaders("Account", "Status")
+	for _, r := range results {
+		status := r.Status
+		if r.Error != "" {
+			status = fmt.Sprintf("%s - %s", status, r.Error)
+		}
+		table.AddRow(r.Account, status)
+	}
+	s := table.Render()
+
+	for _, r := range results {
+		if len(r.Diff) == 0 {
+			continue
+		}
+		table := tablewriter.CreateTable()
+		table.UTF8Box()
+		table.AddTitle(fmt.Sprintf("Conflict in account %q", r.Account))
+		table.AddHeaders("Field", "Local", "Remote")
+		for _, d := range r.Diff {
+			table.AddRow(d.Field, d.Local, d.Remote)
+		}
+		s = s + "\n" + table.Render()
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/nats-io/jwt"
+	"github.com/nats-io/nkeys"
+	"github.com/nats-io/nsc/cmd/store"
+)
+
+// AccountNames maps the public keys of the accounts in the store to their names
+func AccountNames(s *store.Store) (map[string]string, error) {
+	names := make(map[string]string)
+	accounts, err := s.ListSubContainers(store.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range accounts {
+		c, err := s.LoadClaim(store.Accounts, n, store.JwtName(n))
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			names[c.Subject] = n
+		}
+	}
+	return names, nil
+}
+
+// OperatorNames maps the public keys of the operators in the stores directory to their
+// names, directories that are not stores or don't have an operator key are skipped
+func OperatorNames() map[string]string {
+	names := make(map[string]string)
+	config := GetConfig()
+	for _, n := range config.ListOperators() {
+		s, err := config.LoadStore(n)
+		if err != nil {
+			continue
+		}
+		pk, err := s.GetRootPublicKey()
+		if err != nil || pk == "" {
+			continue
+		}
+		names[pk] = n
+	}
+	return names
+}
+
+// resolveTrustedAccounts returns the public keys of accounts specified by
+// public key, or by name or public key prefix of an account in the store
+func resolveTrustedAccounts(s *store.Store, values []string) ([]string, error) {
+	var keys []string
+	for _, v := range values {
+		if nkeys.IsValidPublicAccountKey(v) {
+			keys = append(keys, v)
+			continue
+		}
+		n, err := s.ResolveAccount(v)
+		if err != nil {
+			return nil, err
+		}
+		var ac *jwt.AccountClaims
+		if s.Has(store.Accounts, n, store.JwtName(n)) {
+			if ac, err = s.ReadAccountClaim(n); err != nil {
+				return nil, err
+			}
+		}
+		if ac == nil {
+			return nil, fmt.Errorf("%q is not an account public key or an account in the store", v)
+		}
+		keys = append(keys, ac.Subject)
+	}
+	return keys, nil
+}
+
+// resolveTrustedOperators returns the public keys of operators specified by
+// public key, or by name of an operator in the stores directory
+func resolveTrustedOperators(values []string) ([]string, error) {
+	var keys []string
+	var names map[string]string
+	for _, v := range values {
+		if nkeys.IsValidPublicOperatorKey(v) {
+			keys = append(keys, v)
+			continue
+		}
+		if names == nil {
+			names = OperatorNames()
+		}
+		pk := ""
+		for k, n := range names {
+			if n == v {
+				pk = k
+				break
+			}
+		}
+		if pk == "" {
+			return nil, fmt.Errorf("%q is not an operator public key or an operator in the stores directory", v)
+		}
+		keys = append(keys, pk)
+	}
+	return keys, nil
+}
+
+// withNames formats public keys with the names of the entities in names
+func withNames(keys []string, names map[string]string) []string {
+	var v []string
+	for _, k := range keys {
+		if n, ok := names[k]; ok {
+			v = append(v, fmt.Sprintf("%s (%s)", k, n))
+		} else {
+			v = append(v, k)
+		}
+	}
+	return v
+}
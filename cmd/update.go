@@ -16,52 +16,133 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/blang/semver"
 	"github.com/briandowns/spinner"
+	"github.com/nats-io/nkeys"
 	"github.com/nats-io/nsc/cli"
 	"github.com/rhysd/go-github-selfupdate/selfupdate"
 	"github.com/spf13/cobra"
 )
 
+// ReleaseSigningKey is the public nkey that signs the release checksums, it is
+// set at build time with -ldflags "-X github.com/nats-io/nsc/cmd.ReleaseSigningKey=..."
+// Builds without a key use the update_signing_key setting in the configuration, and
+// without either updates are refused unless --allow-unsigned is specified.
+var ReleaseSigningKey = ""
+
+// suffix of the previous binary kept next to the current one
+const previousVersionSuffix = ".previous"
+
 func createUpdateCommand() *cobra.Command {
 	var printReleaseNotes bool
+	var version string
+	var rollback bool
+	var backgroundChecks bool
+	var allowUnsigned bool
 	var cmd = &cobra.Command{
 		Example: `update
 update --release-notes
+update --version 0.1.0
+update --rollback
+update --background-checks=false
 `,
 		Use:   "update",
 		Short: "Update this tool to latest version",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			v := semver.MustParse(GetRootCmd().Version)
+			if cmd.Flag("background-checks").Changed {
+				config := GetConfig()
+				config.DisableUpdateChecks = !backgroundChecks
+				if err := config.Save(); err != nil {
+					return err
+				}
+				if backgroundChecks {
+					cmd.Println("Background update checks are enabled")
+				} else {
+					cmd.Println("Background update checks are disabled")
+				}
+				return nil
+			}
 
 			su, err := NewSelfUpdate()
 			if err != nil {
 				return err
 			}
-			nvs, err := su.doCheck()
+			su.AllowUnsigned = allowUnsigned
+			exe, err := os.Executable()
 			if err != nil {
 				return err
 			}
-			if nvs == nil {
-				cmd.Println("Current version", v, "is the latest")
+
+			if rollback {
+				if version != "" {
+					return errors.New("--version and --rollback are mutually exclusive")
+				}
+				if err := su.Rollback(exe); err != nil {
+					return err
+				}
+				cmd.Printf("Successfully restored the previous version - %q is the version that was replaced\n", exe+previousVersionSuffix)
 				return nil
 			}
 
+			v := semver.MustParse(GetRootCmd().Version)
+			var release *selfupdate.Release
+			if version == "" {
+				release, err = su.latest()
+				if err != nil {
+					return err
+				}
+				if release == nil {
+					cmd.Println("Current version", v, "is the latest")
+					return nil
+				}
+			} else {
+				release, err = su.version(version)
+				if err != nil {
+					return err
+				}
+				if release.Version.Equals(v) {
+					cmd.Println("Current version is", v)
+					return nil
+				}
+			}
+
+			direct := updateFn != nil && version == ""
+			if !direct {
+				if err := su.verifiable(); err != nil {
+					return err
+				}
+				if su.signingKey() == "" {
+					cmd.Println("Warning: a release signing key is not configured - the update is only verified against its checksum")
+				}
+			}
+
 			wait := spinner.New(spinner.CharSets[14], 250*time.Millisecond)
 			defer wait.Stop()
 
-			wait.Prefix = "Downloading latest version "
+			wait.Prefix = fmt.Sprintf("Downloading version %s ", release.Version)
 			_ = wait.Color("italic")
 			wait.Start()
 
+			if err := su.Backup(exe); err != nil {
+				return err
+			}
+
 			var latest *selfupdate.Release
-			if updateFn == nil {
-				latest, err = selfupdate.UpdateSelf(v, GetConfig().GithubUpdates)
-			} else {
+			if direct {
 				latest, err = updateFn(v, GetConfig().GithubUpdates)
+			} else {
+				err = su.install(release, exe)
+				latest = release
 			}
 			if err != nil {
 				cmd.SilenceErrors = false
@@ -81,6 +162,10 @@ update --release-notes
 	}
 
 	cmd.Flags().BoolVarP(&printReleaseNotes, "release-notes", "r", false, "prints the release notes")
+	cmd.Flags().StringVarP(&version, "version", "", "", "update to the specified version instead of the latest")
+	cmd.Flags().BoolVarP(&rollback, "rollback", "", false, "restore the version that was replaced by the last update")
+	cmd.Flags().BoolVarP(&backgroundChecks, "background-checks", "", true, "enable or disable the daily check for updates")
+	cmd.Flags().BoolVarP(&allowUnsigned, "allow-unsigned", "", false, "install an update verified only against its checksum when a release signing key is not configured")
 
 	return cmd
 }
@@ -91,11 +176,17 @@ func init() {
 
 type UpdateCheckFn func(slug string) (*selfupdate.Release, bool, error)
 type UpdateFn func(current semver.Version, slug string) (*selfupdate.Release, error)
+type DetectVersionFn func(slug string, version string) (*selfupdate.Release, bool, error)
+type InstallFn func(release *selfupdate.Release, cmdPath string) error
 
 var updateCheckFn UpdateCheckFn
 var updateFn UpdateFn
+var detectVersionFn DetectVersionFn
+var installFn InstallFn
 
 type SelfUpdate struct {
+	// AllowUnsigned installs updates without a signing key, verifying only their checksum
+	AllowUnsigned bool
 }
 
 // NewSelfUpdate creates a new self update object
@@ -121,6 +212,9 @@ func (u *SelfUpdate) Run() (*semver.Version, error) {
 
 func (u *SelfUpdate) shouldCheck() bool {
 	config := GetConfig()
+	if config.DisableUpdateChecks {
+		return false
+	}
 	now := time.Now().Unix()
 	diff := now - config.LastUpdate
 
@@ -137,7 +231,41 @@ func (u *SelfUpdate) updateCheckFn() (*selfupdate.Release, bool, error) {
 	return selfupdate.DetectLatest(config.GithubUpdates)
 }
 
+// signingKey returns the public key that signs release checksums or "" if not configured,
+// the configuration only provides the key for builds without one
+func (u *SelfUpdate) signingKey() string {
+	if ReleaseSigningKey != "" {
+		return ReleaseSigningKey
+	}
+	return GetConfig().UpdateSigningKey
+}
+
+// verifiable returns an error if the signature of updates can't be verified, unless
+// unsigned updates are allowed
+func (u *SelfUpdate) verifiable() error {
+	if u.signingKey() == "" && !u.AllowUnsigned {
+		return errors.New("a release signing key is not configured - unable to verify the update, specify --allow-unsigned to only verify its checksum")
+	}
+	return nil
+}
+
+// updater returns an updater that finds and verifies the checksum of the release assets,
+// the signature of the checksum is verified when a signing key is configured
+func (u *SelfUpdate) updater() (*selfupdate.Updater, error) {
+	conf := selfupdate.Config{Validator: &ChecksumValidator{PublicKey: u.signingKey()}}
+	return selfupdate.NewUpdater(conf)
+}
+
 func (u *SelfUpdate) doCheck() (*semver.Version, error) {
+	r, err := u.latest()
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return &r.Version, nil
+}
+
+// latest returns the latest release if it is newer than the running version
+func (u *SelfUpdate) latest() (*selfupdate.Release, error) {
 	config := GetConfig()
 	have := semver.MustParse(GetRootCmd().Version)
 	wait := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
@@ -150,14 +278,141 @@ func (u *SelfUpdate) doCheck() (*semver.Version, error) {
 	var found bool
 	var err error
 	if updateCheckFn == nil {
-		latest, found, err = selfupdate.DetectLatest(config.GithubUpdates)
+		var up *selfupdate.Updater
+		up, err = u.updater()
+		if err == nil {
+			latest, found, err = up.DetectLatest(config.GithubUpdates)
+		}
 	} else {
 		latest, found, err = updateCheckFn(config.GithubUpdates)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error checking version: %v", err)
 	} else if found && latest.Version.GT(have) {
-		return &latest.Version, nil
+		return latest, nil
 	}
 	return nil, nil
 }
+
+// version returns the release for the specified version
+func (u *SelfUpdate) version(version string) (*selfupdate.Release, error) {
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "v"), "V")
+	if _, err := semver.Parse(version); err != nil {
+		return nil, fmt.Errorf("%q is not a valid version: %v", version, err)
+	}
+	config := GetConfig()
+	var r *selfupdate.Release
+	var found bool
+	var err error
+	if detectVersionFn == nil {
+		var up *selfupdate.Updater
+		up, err = u.updater()
+		if err == nil {
+			r, found, err = up.DetectVersion(config.GithubUpdates, version)
+		}
+	} else {
+		r, found, err = detectVersionFn(config.GithubUpdates, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error checking version: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("version %s was not found", version)
+	}
+	return r, nil
+}
+
+// install replaces the binary at cmdPath with the release after verifying its signed checksum
+func (u *SelfUpdate) install(r *selfupdate.Release, cmdPath string) error {
+	if err := u.verifiable(); err != nil {
+		return err
+	}
+	if installFn != nil {
+		return installFn(r, cmdPath)
+	}
+	if r.ValidationAssetID == 0 {
+		return fmt.Errorf("release %s doesn't provide a checksum", r.Version)
+	}
+	up, err := u.updater()
+	if err != nil {
+		return err
+	}
+	return up.UpdateTo(r, cmdPath)
+}
+
+// Backup copies the binary at cmdPath next to it, so that it can be restored by Rollback
+func (u *SelfUpdate) Backup(cmdPath string) error {
+	src, err := os.Open(cmdPath)
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", cmdPath, err)
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	fp := cmdPath + previousVersionSuffix
+	dst, err := os.OpenFile(fp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return fmt.Errorf("error saving the current version to %q: %v", fp, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return fmt.Errorf("error saving the current version to %q: %v", fp, err)
+	}
+	return dst.Close()
+}
+
+// Rollback swaps the binary at cmdPath with the one saved by Backup
+func (u *SelfUpdate) Rollback(cmdPath string) error {
+	previous := cmdPath + previousVersionSuffix
+	if _, err := os.Stat(previous); os.IsNotExist(err) {
+		return errors.New("there's no previous version to rollback to")
+	}
+	tmp := cmdPath + ".rollback"
+	if err := os.Rename(cmdPath, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(previous, cmdPath); err != nil {
+		_ = os.Rename(tmp, cmdPath)
+		return err
+	}
+	return os.Rename(tmp, previous)
+}
+
+// ChecksumValidator verifies release assets against a checksum file signed with an nkey. The
+// file contains the hex encoded sha256 of the asset followed by a line with the base64url
+// encoded signature of the checksum. Without a PublicKey only the checksum is verified and
+// the signature line is optional.
+type ChecksumValidator struct {
+	PublicKey string
+}
+
+func (v *ChecksumValidator) Validate(release, asset []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(asset)), "\n")
+	if len(lines) > 2 || (v.PublicKey != "" && len(lines) != 2) {
+		return errors.New("checksum file is not valid")
+	}
+	checksum := strings.TrimSpace(lines[0])
+	if v.PublicKey != "" {
+		sig, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(lines[1]))
+		if err != nil {
+			return fmt.Errorf("checksum signature is not valid: %v", err)
+		}
+		kp, err := nkeys.FromPublicKey(v.PublicKey)
+		if err != nil {
+			return fmt.Errorf("release signing key is not valid: %v", err)
+		}
+		if err := kp.Verify([]byte(checksum), sig); err != nil {
+			return fmt.Errorf("checksum signature verification failed: %v", err)
+		}
+	}
+	if !bytes.Equal([]byte(checksum), []byte(fmt.Sprintf("%x", sha256.Sum256(release)))) {
+		return errors.New("checksum doesn't match the downloaded release")
+	}
+	return nil
+}
+
+func (v *ChecksumValidator) Suffix() string {
+	return ".sha256.sig"
+}
@@ -16,7 +16,12 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -136,3 +141,173 @@ func TestUpdate_DoUpdate(t *testing.T) {
 	require.True(t, updateCalled)
 	require.Contains(t, stderr, "f33dfac3")
 }
+
+func TestUpdate_DisabledChecks(t *testing.T) {
+	d := MakeTempDir(t)
+	_ = os.Setenv(NscHomeEnv, d)
+	conf := GetConfig()
+	conf.GithubUpdates = "foo/bar"
+	conf.SetVersion("1.0.0")
+	conf.LastUpdate = 0
+	conf.DisableUpdateChecks = true
+
+	var checkCalled bool
+	updateCheckFn = func(slug string) (*selfupdate.Release, bool, error) {
+		checkCalled = true
+		return nil, false, nil
+	}
+	defer func() {
+		_ = os.Setenv(NscHomeEnv, "")
+		conf.DisableUpdateChecks = false
+		updateCheckFn = nil
+	}()
+
+	su, err := NewSelfUpdate()
+	require.NoError(t, err)
+	require.False(t, su.shouldCheck())
+	v, err := su.Run()
+	require.NoError(t, err)
+	require.Nil(t, v)
+	require.False(t, checkCalled)
+}
+
+func TestUpdate_BackgroundChecksFlag(t *testing.T) {
+	d := MakeTempDir(t)
+	_ = os.Setenv(NscHomeEnv, d)
+	defer configHome(t)()
+	conf := GetConfig()
+	conf.GithubUpdates = "foo/bar"
+	defer func() {
+		_ = os.Setenv(NscHomeEnv, "")
+		conf.DisableUpdateChecks = false
+	}()
+
+	_, stderr, err := ExecuteCmd(createUpdateCommand(), "--background-checks=false")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Background update checks are disabled")
+	require.True(t, conf.DisableUpdateChecks)
+
+	_, stderr, err = ExecuteCmd(createUpdateCommand(), "--background-checks")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Background update checks are enabled")
+	require.False(t, conf.DisableUpdateChecks)
+}
+
+func TestUpdate_Version(t *testing.T) {
+	d := MakeTempDir(t)
+	_ = os.Setenv(NscHomeEnv, d)
+	conf := GetConfig()
+	conf.GithubUpdates = "foo/bar"
+	conf.SetVersion("1.0.0")
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	var requested string
+	var installed *selfupdate.Release
+	detectVersionFn = func(slug string, version string) (*selfupdate.Release, bool, error) {
+		requested = version
+		var r selfupdate.Release
+		r.Version = semver.MustParse(version)
+		return &r, true, nil
+	}
+	installFn = func(release *selfupdate.Release, cmdPath string) error {
+		require.Equal(t, exe, cmdPath)
+		installed = release
+		return nil
+	}
+	defer func() {
+		_ = os.Setenv(NscHomeEnv, "")
+		_ = os.Remove(exe + previousVersionSuffix)
+		detectVersionFn = nil
+		installFn = nil
+	}()
+
+	// without a signing key updates are refused unless allowed
+	_, _, err = ExecuteCmd(createUpdateCommand(), "--version", "v0.9.0")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "a release signing key is not configured")
+	require.Nil(t, installed)
+	_, stderr, err := ExecuteCmd(createUpdateCommand(), "--version", "v0.9.0", "--allow-unsigned")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "only verified against its checksum")
+	require.NotNil(t, installed)
+	installed = nil
+
+	_, pub, _ := CreateAccountKey(t)
+	conf.UpdateSigningKey = pub
+	defer func() {
+		conf.UpdateSigningKey = ""
+	}()
+	_, stderr, err = ExecuteCmd(createUpdateCommand(), "--version", "v0.9.0")
+	require.NoError(t, err)
+	require.NotContains(t, stderr, "only verified against its checksum")
+	require.Equal(t, "0.9.0", requested)
+	require.NotNil(t, installed)
+	require.Equal(t, "0.9.0", installed.Version.String())
+	require.Contains(t, stderr, "Successfully updated to version 0.9.0")
+	require.FileExists(t, exe+previousVersionSuffix)
+
+	_, _, err = ExecuteCmd(createUpdateCommand(), "--version", "x")
+	require.Error(t, err)
+}
+
+func TestUpdate_Rollback(t *testing.T) {
+	d := MakeTempDir(t)
+	fp := filepath.Join(d, "nsc")
+	require.NoError(t, ioutil.WriteFile(fp, []byte("old"), 0700))
+
+	su := &SelfUpdate{}
+	require.Error(t, su.Rollback(fp))
+
+	require.NoError(t, su.Backup(fp))
+	require.NoError(t, ioutil.WriteFile(fp, []byte("new"), 0700))
+
+	require.NoError(t, su.Rollback(fp))
+	d1, err := ioutil.ReadFile(fp)
+	require.NoError(t, err)
+	require.Equal(t, "old", string(d1))
+	d2, err := ioutil.ReadFile(fp + previousVersionSuffix)
+	require.NoError(t, err)
+	require.Equal(t, "new", string(d2))
+}
+
+func TestUpdate_ChecksumValidator(t *testing.T) {
+	_, pub, kp := CreateAccountKey(t)
+	release := []byte("release")
+	checksum := fmt.Sprintf("%x", sha256.Sum256(release))
+	sig, err := kp.Sign([]byte(checksum))
+	require.NoError(t, err)
+	asset := []byte(checksum + "\n" + base64.RawURLEncoding.EncodeToString(sig) + "\n")
+
+	v := &ChecksumValidator{PublicKey: pub}
+	require.NoError(t, v.Validate(release, asset))
+	require.Error(t, v.Validate([]byte("tampered"), asset))
+	require.Error(t, v.Validate(release, []byte(checksum)))
+
+	_, other, _ := CreateAccountKey(t)
+	v = &ChecksumValidator{PublicKey: other}
+	require.Error(t, v.Validate(release, asset))
+
+	v = &ChecksumValidator{}
+	require.NoError(t, v.Validate(release, asset))
+	require.NoError(t, v.Validate(release, []byte(checksum)))
+	require.Error(t, v.Validate([]byte("tampered"), asset))
+}
+
+func TestUpdate_SigningKey(t *testing.T) {
+	_, compiled, _ := CreateAccountKey(t)
+	_, configured, _ := CreateAccountKey(t)
+	conf := GetConfig()
+	conf.UpdateSigningKey = configured
+	defer func() {
+		conf.UpdateSigningKey = ""
+		ReleaseSigningKey = ""
+	}()
+
+	su := &SelfUpdate{}
+	require.Equal(t, configured, su.signingKey())
+	// the configuration cannot replace the key of the build
+	ReleaseSigningKey = compiled
+	require.Equal(t, compiled, su.signingKey())
+}
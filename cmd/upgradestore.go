@@ -0,0 +1,190 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+func createUpgradeStoreCmd() *cobra.Command {
+	var params UpgradeStoreParams
+	cmd := &cobra.Command{
+		Use:   "upgrade-store",
+		Short: "Upgrade the store to the format of this nsc",
+		Long: `Upgrade the store to the format of this nsc
+
+The store directory is copied to the backup directory before the pending
+migrations are applied. Stores at another version than the one supported by
+nsc can't be used until they are upgraded.`,
+		Example: `nsc upgrade-store
+nsc upgrade-store --dry-run
+nsc upgrade-store --all-operators --backup-dir /path/backups`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunStoreLessAction(cmd, args, &params); err != nil {
+				return err
+			}
+			cmd.Println(params.Table())
+			switch {
+			case params.count == 0:
+				cmd.Printf("No changes - the stores are at version %s\n", store.Version)
+			case params.dryRun:
+				cmd.Printf("Dry run - %d migration(s) not applied\n", params.count)
+			default:
+				cmd.Printf("Success! - applied %d migration(s)\n", params.count)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&params.all, "all-operators", "", false, "upgrade all the operators in the stores directory")
+	cmd.Flags().StringVarP(&params.backupDir, "backup-dir", "", "", "directory for the backups of the stores (default is the backups directory in the nsc home)")
+	cmd.Flags().BoolVarP(&params.dryRun, "dry-run", "", false, "show the migrations without applying them")
+
+	return cmd
+}
+
+func init() {
+	GetRootCmd().AddCommand(createUpgradeStoreCmd())
+}
+
+// StoreUpgrade describes the migrations of the store of an operator
+type StoreUpgrade struct {
+	Operator   string
+	Version    string
+	Migrations []store.Migration
+	Backup     string
+	store      *store.Store
+}
+
+type UpgradeStoreParams struct {
+	all       bool
+	backupDir string
+	dryRun    bool
+	upgrades  []*StoreUpgrade
+	count     int
+}
+
+func (p *UpgradeStoreParams) SetDefaults(ctx ActionCtx) error {
+	if p.backupDir == "" {
+		if toolHome != "" {
+			p.backupDir = filepath.Join(toolHome, "backups")
+		} else {
+			p.backupDir = filepath.Join(filepath.Dir(GetConfig().StoreRoot), "backups")
+		}
+	}
+	return nil
+}
+
+func (p *UpgradeStoreParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *UpgradeStoreParams) Load(ctx ActionCtx) error {
+	config := GetConfig()
+	if config.StoreRoot == "" {
+		return errors.New("no stores directory - set one with `env --store`")
+	}
+
+	operators := config.ListOperators()
+	if !p.all {
+		if config.Operator == "" {
+			config.SetDefaults()
+		}
+		if config.Operator == "" {
+			return errors.New("set an operator")
+		}
+		operators = []string{config.Operator}
+	}
+	sort.Strings(operators)
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, n := range operators {
+		s, err := store.OpenStoreForUpgrade(filepath.Join(config.StoreRoot, n))
+		if err != nil {
+			return err
+		}
+		pending, err := s.PendingMigrations()
+		if err != nil {
+			return err
+		}
+		u := &StoreUpgrade{Operator: n, Version: s.Info.Version, Migrations: pending, store: s}
+		if len(pending) > 0 {
+			u.Backup = filepath.Join(p.backupDir, fmt.Sprintf("%s-%s", n, stamp))
+		}
+		p.upgrades = append(p.upgrades, u)
+		p.count += len(pending)
+	}
+	return nil
+}
+
+func (p *UpgradeStoreParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *UpgradeStoreParams) Validate(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *UpgradeStoreParams) Run(ctx ActionCtx) error {
+	if p.dryRun {
+		return nil
+	}
+	for _, u := range p.upgrades {
+		if len(u.Migrations) == 0 {
+			continue
+		}
+		applied, err := u.store.Upgrade(u.Backup)
+		if err != nil {
+			if len(applied) > 0 {
+				ctx.CurrentCmd().Printf("upgraded %q to version %d before the error, the backup is in %q\n", u.Operator, applied[len(applied)-1].Version, u.Backup)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Table renders the migrations by operator
+func (p *UpgradeStoreParams) Table() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle("Store Migrations")
+	table.AddHeaders("Operator", "From", "To", "Migration", "Backup")
+	for _, u := range p.upgrades {
+		from := u.Version
+		if from == "" {
+			from = "unversioned"
+		}
+		if len(u.Migrations) == 0 {
+			table.AddRow(u.Operator, from, from, "up to date", "")
+			continue
+		}
+		for _, m := range u.Migrations {
+			table.AddRow(u.Operator, from, strconv.Itoa(m.Version), m.Description, u.Backup)
+			from = strconv.Itoa(m.Version)
+		}
+	}
+	return table.Render()
+}
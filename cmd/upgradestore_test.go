@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/nsc/cmd/store"
+	"github.com/stretchr/testify/require"
+)
+
+func unversionStore(t *testing.T, s *store.Store) {
+	s.Info.Version = ""
+	d, err := json.Marshal(s.Info)
+	require.NoError(t, err)
+	require.NoError(t, s.Write(d, store.NSCFile))
+}
+
+func Test_UpgradeStore(t *testing.T) {
+	ts := NewTestStore(t, "upgrade")
+	defer ts.Done(t)
+	ts.AddAccount(t, "A")
+	unversionStore(t, ts.Store)
+
+	_, _, err := ExecuteCmd(createDescribeAccountCmd(), "--account", "A")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "nsc upgrade-store")
+
+	_, stderr, err := ExecuteCmd(createUpgradeStoreCmd(), "--dry-run")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Dry run - 1 migration(s) not applied")
+
+	backups := filepath.Join(ts.Dir, "backups")
+	_, stderr, err = ExecuteCmd(createUpgradeStoreCmd(), "--backup-dir", backups)
+	require.NoError(t, err)
+	stderr = StripTableDecorations(stderr)
+	require.Contains(t, stderr, "upgrade unversioned 1 record the store version in .nsc")
+	require.Contains(t, stderr, "Success! - applied 1 migration(s)")
+
+	infos, err := ioutil.ReadDir(backups)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.FileExists(t, filepath.Join(backups, infos[0].Name(), store.NSCFile))
+
+	_, _, err = ExecuteCmd(createDescribeAccountCmd(), "--account", "A")
+	require.NoError(t, err)
+
+	_, stderr, err = ExecuteCmd(createUpgradeStoreCmd(), "--backup-dir", backups)
+	require.NoError(t, err)
+	require.Contains(t, stderr, "No changes - the stores are at version 1")
+}
+
+func Test_UpgradeStoreAllOperators(t *testing.T) {
+	ts := NewTestStore(t, "upgrade")
+	defer ts.Done(t)
+	unversionStore(t, ts.Store)
+	unversionStore(t, ts.AddOperator(t, "other"))
+
+	_, stderr, err := ExecuteCmd(createUpgradeStoreCmd(), "--all-operators", "--backup-dir", filepath.Join(ts.Dir, "backups"))
+	require.NoError(t, err)
+	require.Contains(t, stderr, "Success! - applied 2 migration(s)")
+	for _, n := range []string{"upgrade", "other"} {
+		_, err := store.LoadStore(filepath.Join(ts.GetStoresRoot(), n))
+		require.NoError(t, err)
+	}
+}
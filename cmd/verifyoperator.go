@@ -0,0 +1,170 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/nats-io/jwt"
+	"github.com/spf13/cobra"
+	"github.com/xlab/tablewriter"
+)
+
+const (
+	IdentityVerified   = "verified"
+	IdentityNoDocument = "not checked - no document"
+	IdentityNotFound   = "proof not in the document"
+	IdentityInvalid    = "invalid proof"
+)
+
+func createVerifyOperatorCmd() *cobra.Command {
+	var params VerifyOperatorParams
+	cmd := &cobra.Command{
+		Use:   "operator",
+		Short: "Verify the identity proofs of the operator",
+		Long: `Verify the identity proofs of the operator
+
+Each document is the content published at the location of an identity, such
+as the output of 'dig TXT example.com' for dns:example.com or the document
+downloaded from the URL. An identity is verified when its proof is signed by
+the operator and appears in its document.`,
+		Example: `nsc verify operator --document dns:example.com=txt.dump
+nsc verify operator --document https://example.com/nats.txt=nats.txt`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := RunAction(cmd, args, &params); err != nil {
+				return err
+			}
+			_ = Write("--", []byte(params.Table()))
+			if params.failed > 0 {
+				return fmt.Errorf("%d identity proof(s) failed verification", params.failed)
+			}
+			if params.verified == 0 {
+				return fmt.Errorf("no identity proofs were checked - specify their documents")
+			}
+			cmd.Printf("Success! - verified %d identity proof(s)\n", params.verified)
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVarP(&params.documents, "document", "d", nil, "document for an identity as <id>=<file> - option can be specified multiple times")
+
+	return cmd
+}
+
+func init() {
+	verifyCmd.AddCommand(createVerifyOperatorCmd())
+}
+
+// IdentityCheck is the result of verifying an identity
+type IdentityCheck struct {
+	jwt.Identity
+	Document string
+	Status   string
+}
+
+type VerifyOperatorParams struct {
+	documents []string
+	files     map[string]string
+	claim     *jwt.OperatorClaims
+	checks    []IdentityCheck
+	verified  int
+	failed    int
+}
+
+func (p *VerifyOperatorParams) SetDefaults(ctx ActionCtx) error {
+	p.files = make(map[string]string)
+	for _, v := range p.documents {
+		i := strings.Index(v, "=")
+		if i == -1 {
+			ctx.CurrentCmd().SilenceUsage = false
+			return fmt.Errorf("document %q is not in the <id>=<file> format", v)
+		}
+		p.files[v[:i]] = v[i+1:]
+	}
+	return nil
+}
+
+func (p *VerifyOperatorParams) PreInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *VerifyOperatorParams) Load(ctx ActionCtx) error {
+	var err error
+	p.claim, err = ReadOperatorClaim(ctx.StoreCtx().Store)
+	return err
+}
+
+func (p *VerifyOperatorParams) PostInteractive(ctx ActionCtx) error {
+	return nil
+}
+
+func (p *VerifyOperatorParams) Validate(ctx ActionCtx) error {
+	if len(p.claim.Identities) == 0 {
+		return fmt.Errorf("operator %q doesn't have identities", p.claim.Name)
+	}
+	for id := range p.files {
+		if findIdentity(p.claim.Identities, id) == -1 {
+			return fmt.Errorf("operator doesn't have an identity %q", id)
+		}
+	}
+	return nil
+}
+
+func (p *VerifyOperatorParams) Run(ctx ActionCtx) error {
+	for _, id := range p.claim.Identities {
+		c := IdentityCheck{Identity: id, Document: p.files[id.ID]}
+		switch {
+		case VerifyIdentityProof(p.claim.Subject, id) != nil:
+			c.Status = IdentityInvalid
+		case c.Document == "":
+			c.Status = IdentityNoDocument
+		default:
+			d, err := ioutil.ReadFile(c.Document)
+			if err != nil {
+				return fmt.Errorf("error reading %q: %v", c.Document, err)
+			}
+			if strings.Contains(string(d), id.Proof) {
+				c.Status = IdentityVerified
+			} else {
+				c.Status = IdentityNotFound
+			}
+		}
+
+		switch c.Status {
+		case IdentityVerified:
+			p.verified++
+		case IdentityNoDocument:
+		default:
+			p.failed++
+		}
+		p.checks = append(p.checks, c)
+	}
+	return nil
+}
+
+// Table renders the identity checks
+func (p *VerifyOperatorParams) Table() string {
+	table := tablewriter.CreateTable()
+	table.UTF8Box()
+	table.AddTitle(fmt.Sprintf("Identities of Operator %q", p.claim.Name))
+	table.AddHeaders("ID", "Document", "Status")
+	for _, c := range p.checks {
+		table.AddRow(c.ID, valueOrNone(c.Document), c.Status)
+	}
+	return table.Render()
+}
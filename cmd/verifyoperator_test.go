@@ -0,0 +1,54 @@
+/*
+ * Copyright 2018 The NATS Authors
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_VerifyOperator(t *testing.T) {
+	ts := NewTestStore(t, "operator")
+	defer ts.Done(t)
+
+	_, _, err := ExecuteCmd(createVerifyOperatorCmd())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "doesn't have identities")
+
+	proof := identityProof(t, ts, "dns:example.com")
+	_, _, err = ExecuteCmd(createEditOperatorCmd(), "--add-identity", fmt.Sprintf("dns:example.com=%s", proof))
+	require.NoError(t, err)
+
+	txt := filepath.Join(ts.Dir, "txt.dump")
+	dump := fmt.Sprintf("example.com.\t300\tIN\tTXT\t\"v=spf1 -all\"\nexample.com.\t300\tIN\tTXT\t%q\n", proof)
+	require.NoError(t, ioutil.WriteFile(txt, []byte(dump), 0644))
+	other := filepath.Join(ts.Dir, "other.dump")
+	require.NoError(t, ioutil.WriteFile(other, []byte("example.com.\t300\tIN\tTXT\t\"v=spf1 -all\"\n"), 0644))
+
+	tests := CmdTests{
+		{createVerifyOperatorCmd(), []string{"verify", "operator"}, nil, []string{"no identity proofs were checked"}, true},
+		{createVerifyOperatorCmd(), []string{"verify", "operator", "--document", txt}, nil, []string{"is not in the <id>=<file> format"}, true},
+		{createVerifyOperatorCmd(), []string{"verify", "operator", "--document", "dns:example.org=" + txt}, nil, []string{"doesn't have an identity \"dns:example.org\""}, true},
+		{createVerifyOperatorCmd(), []string{"verify", "operator", "--document", "dns:example.com=" + other}, []string{IdentityNotFound}, []string{"1 identity proof(s) failed verification"}, true},
+		{createVerifyOperatorCmd(), []string{"verify", "operator", "--document", "dns:example.com=" + txt}, []string{IdentityVerified}, []string{"verified 1 identity proof(s)"}, false},
+	}
+
+	tests.Run(t, "root", "verify")
+}
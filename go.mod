@@ -21,9 +21,11 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rhysd/go-github-selfupdate v1.1.0
 	github.com/spf13/cobra v0.0.3
+	github.com/spf13/pflag v1.0.2
 	github.com/spf13/viper v1.2.1
 	github.com/stretchr/testify v1.2.2
 	github.com/xlab/tablewriter v0.0.0-20160610135559-80b567a11ad5
-	golang.org/x/crypto v0.0.0-20181126163421-e657309f52e7 // indirect
+	golang.org/x/crypto v0.0.0-20181126163421-e657309f52e7
 	gopkg.in/AlecAivazis/survey.v1 v1.7.0 // indirect
+	gopkg.in/yaml.v2 v2.2.1
 )
@@ -0,0 +1,23 @@
+#!/bin/bash -e
+# this script is intended for goreleaser - see the sign section in .goreleaser.yml
+# Usage: ./scripts/sign-release.sh <artifact> <signature>
+#
+# Writes the hex encoded sha256 of the artifact followed by its signature with the
+# seed in NSC_RELEASE_SIGNING_SEED. `nsc update` verifies the signature with the
+# ReleaseSigningKey that is set from NSC_RELEASE_SIGNING_KEY at build time.
+
+if [[ -z $NSC_RELEASE_SIGNING_SEED ]]; then
+    echo "NSC_RELEASE_SIGNING_SEED is not set" >&2
+    exit 1
+fi
+
+TMP=$(mktemp -d)
+trap 'rm -rf "$TMP"' EXIT
+
+echo "$NSC_RELEASE_SIGNING_SEED" > "$TMP/seed"
+sha256sum "$1" | cut -d ' ' -f 1 | tr -d '\n' > "$TMP/checksum"
+# nk prints standard base64, nsc expects it raw url encoded
+SIG=$(nk -sign "$TMP/checksum" -inkey "$TMP/seed" | tr '+/' '-_' | tr -d '=')
+
+cat "$TMP/checksum" > "$2"
+printf '\n%s\n' "$SIG" >> "$2"